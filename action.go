@@ -30,10 +30,32 @@ type dropItem struct {
 }
 
 func (a dropItem) Perform() error {
+	if a.Item.Quantity == 1 && a.Entity.Equipment.IsEquipped(a.Item) {
+		a.Entity.Equipment.ToggleEquip(a.Item, true)
+	}
 	a.Entity.Inventory.Drop(a.Item)
 	return nil
 }
 
+type equipAction struct {
+	baseAction
+	Item *item
+}
+
+func newEquipAction(entity *actor, it *item) *equipAction {
+	return &equipAction{
+		baseAction: baseAction{
+			Entity: entity,
+		},
+		Item: it,
+	}
+}
+
+func (a *equipAction) Perform() error {
+	a.Entity.Equipment.ToggleEquip(a.Item, true)
+	return nil
+}
+
 type waitAction struct{}
 
 func (a waitAction) Perform() error {
@@ -75,31 +97,204 @@ func (a meleeAction) Perform() error {
 	if target == nil {
 		return impossible{"Nothing to attack."}
 	}
+	if !a.Entity.IsHostile(target) {
+		return impossible{fmt.Sprintf("You cannot bring yourself to attack the %s.", target.Name)}
+	}
+
+	resolveAttack(a.Engine(), a.Entity, target, fmt.Sprintf("%s attacks %s", a.Entity.Name, target.Name), a.Entity.Fighter.DamageDice(), a.Entity.Fighter.AttackDamageType())
+	return nil
+}
 
-	damage := a.Entity.Fighter.Power - target.Fighter.Defense
+// resolveAttack rolls attacker's attack against target and reports the
+// outcome, prefixed by attackDesc, as long as the player can see either of
+// them. A d20 plus the attacker's power has to reach 10 plus the target's
+// evasion; a natural 1 always misses and a natural 20 is a critical hit that
+// rolls damageDice twice and always deals at least 1 damage.
+func resolveAttack(e *engine, attacker, target *actor, attackDesc string, damageDice dice, dt damageType) {
+	e.MakeNoise(attacker.X, attacker.Y, noiseCombat)
 
-	attackDesc := fmt.Sprintf("%s attacks %s", a.Entity.Name, target.Name)
 	attackColor := ColorEnemyAtk
-	if a.Entity == a.Engine().Player {
+	if attacker == e.Player {
 		attackColor = ColorPlayerAtk
 	}
+	visible := e.GameMap.Visible[attacker.X][attacker.Y] || e.GameMap.Visible[target.X][target.Y]
+
+	roll := e.Rand.Intn(20) + 1
+	critical := roll == 20
+	if roll == 1 || (!critical && roll+attacker.Fighter.Power() < 10+target.Fighter.Evasion) {
+		if visible {
+			e.MessageLog.AddMessage(fmt.Sprintf("%s but misses.", attackDesc), attackColor, true)
+		}
+		return
+	}
+
+	damage := damageDice.Roll(e.Rand)
+	if critical {
+		damage += damageDice.Roll(e.Rand) - damageDice.Bonus
+		attackDesc = fmt.Sprintf("Critical hit! %s", attackDesc)
+	}
+	damage -= target.Fighter.Defense()
+	if critical && damage < 1 {
+		damage = 1
+	}
+	rawDamage := damage
+	damage = target.Fighter.Resist(rawDamage, dt)
+
 	if damage > 0 {
-		a.Engine().MessageLog.AddMessage(
-			fmt.Sprintf("%s for %d hit points.", attackDesc, damage),
-			attackColor,
-			true,
-		)
-		target.Fighter.TakeDamage(damage)
-	} else {
-		a.Engine().MessageLog.AddMessage(
+		if visible {
+			e.MessageLog.AddMessage(
+				fmt.Sprintf("%s for %d hit points.", attackDesc, damage),
+				attackColor,
+				true,
+			)
+		}
+		target.Fighter.TakeDamage(rawDamage, dt, attacker)
+	} else if visible {
+		e.MessageLog.AddMessage(
 			fmt.Sprintf("%s but does no damage.", attackDesc),
 			attackColor,
 			true,
 		)
 	}
+}
+
+type rangedAttackAction struct {
+	baseAction
+	TargetXY   [2]int
+	Projectile string
+	DamageType damageType
+}
+
+func (a rangedAttackAction) Perform() error {
+	gm := a.Engine().GameMap
+	_, target := projectilePath(gm, a.Entity.X, a.Entity.Y, a.TargetXY[0], a.TargetXY[1])
+	if target == nil {
+		if gm.Visible[a.Entity.X][a.Entity.Y] {
+			a.Engine().MessageLog.AddMessage(fmt.Sprintf("%s fires %s, but it hits nothing.", a.Entity.Name, a.Projectile), ColorEnemyAtk, true)
+		}
+		return nil
+	}
+
+	resolveAttack(a.Engine(), a.Entity, target, fmt.Sprintf("%s fires %s at %s", a.Entity.Name, a.Projectile, target.Name), a.Entity.Fighter.DamageDice(), a.DamageType)
 	return nil
 }
 
+// projectilePath follows the bresenham line from (x1, y1) to (x2, y2) and
+// returns the tiles a projectile flies through. The flight stops short of the
+// first wall and at the first actor in the way, which is returned as well.
+func projectilePath(gm *gameMap, x1, y1, x2, y2 int) ([][2]int, *actor) {
+	line := [][2]int{}
+	for tup := range bresenham(x1, y1, x2, y2) {
+		line = append(line, tup)
+	}
+	path := [][2]int{}
+	for _, tup := range line[1:] {
+		if !gm.InBounds(tup[0], tup[1]) || !gm.Walkable(tup[0], tup[1]) {
+			return path, nil
+		}
+		path = append(path, tup)
+		if a := gm.GetAcotrAtLocation(tup[0], tup[1]); a != nil {
+			return path, a
+		}
+	}
+	return path, nil
+}
+
+type throwAction struct {
+	itemAction
+}
+
+func newThrowAction(entity *actor, it *item, targetXY [2]int) *throwAction {
+	return &throwAction{
+		itemAction: *newItemAction(entity, it, &targetXY),
+	}
+}
+
+func (a *throwAction) Perform() error {
+	gm := a.Engine().GameMap
+	if a.TargetXY == [2]int{a.Entity.X, a.Entity.Y} {
+		return impossible{"You cannot throw at yourself."}
+	}
+	if a.Entity == a.Engine().Player && !gm.Visible[a.TargetXY[0]][a.TargetXY[1]] {
+		return impossible{"You cannot target an area that you cannot see."}
+	}
+
+	if a.Item.Quantity == 1 && a.Entity.Equipment.IsEquipped(a.Item) {
+		a.Entity.Equipment.Unequip(a.Item, true)
+	}
+	thrown := a.Entity.Inventory.Take(a.Item)
+
+	path, target := projectilePath(gm, a.Entity.X, a.Entity.Y, a.TargetXY[0], a.TargetXY[1])
+	if target != nil {
+		resolveAttack(a.Engine(), a.Entity, target, fmt.Sprintf("%s throws the %s at %s", a.Entity.Name, thrown.DisplayName(), target.Name), thrown.ThrownDamage(), damagePhysical)
+	}
+	landAt(gm, a.Entity, thrown, path)
+	return nil
+}
+
+type fireAction struct {
+	baseAction
+	TargetXY [2]int
+}
+
+func newFireAction(entity *actor, targetXY [2]int) *fireAction {
+	return &fireAction{
+		baseAction: baseAction{
+			Entity: entity,
+		},
+		TargetXY: targetXY,
+	}
+}
+
+func (a *fireAction) Perform() error {
+	gm := a.Engine().GameMap
+	eq := a.Entity.Equipment
+	if err := eq.CanFire(); err != nil {
+		return err
+	}
+	if a.TargetXY == [2]int{a.Entity.X, a.Entity.Y} {
+		return impossible{"You cannot fire at yourself."}
+	}
+	if a.Entity == a.Engine().Player && !gm.Visible[a.TargetXY[0]][a.TargetXY[1]] {
+		return impossible{"You cannot target an area that you cannot see."}
+	}
+
+	quivered := eq.Slots[slotQuiver]
+	ammo := a.Entity.Inventory.Take(quivered)
+	if ammo == quivered {
+		eq.Unequip(ammo, false)
+	}
+
+	path, target := projectilePath(gm, a.Entity.X, a.Entity.Y, a.TargetXY[0], a.TargetXY[1])
+	if target != nil {
+		resolveAttack(a.Engine(), a.Entity, target, fmt.Sprintf("%s fires the %s at %s", a.Entity.Name, ammo.Name, target.Name), ammo.Equippable.Damage, damagePhysical)
+	} else if gm.Visible[a.Entity.X][a.Entity.Y] {
+		a.Engine().MessageLog.AddMessage(fmt.Sprintf("%s fires the %s, but it hits nothing.", a.Entity.Name, ammo.Name), ColorWhite, true)
+	}
+	landAt(gm, a.Entity, ammo, path)
+
+	if ammo == quivered && !eq.Reload(ammo.Equippable.AmmoKind) && a.Entity == a.Engine().Player {
+		a.Engine().MessageLog.AddMessage(fmt.Sprintf("You are out of %s.", ammo.Equippable.AmmoKind), ColorWhite, true)
+	}
+	return nil
+}
+
+// landAt drops a thrown or fired item at the end of its flight path, or
+// breaks it if it is fragile.
+func landAt(gm *gameMap, thrower *actor, it *item, path [][2]int) {
+	x, y := thrower.X, thrower.Y
+	if len(path) > 0 {
+		x, y = path[len(path)-1][0], path[len(path)-1][1]
+	}
+	if it.Fragile {
+		if gm.Visible[x][y] {
+			gm.Engine.MessageLog.AddMessage(fmt.Sprintf("The %s shatters!", it.DisplayName()), ColorWhite, true)
+		}
+		return
+	}
+	it.Place(x, y, gm)
+}
+
 type movementAction struct {
 	actionWithDirection
 }
@@ -117,6 +312,29 @@ func (a movementAction) Perform() error {
 	}
 
 	a.Entity.Move(a.Dx, a.Dy)
+	if a.Entity == a.Engine().Player {
+		a.Engine().AutopickupItems()
+	}
+	return nil
+}
+
+type swapAction struct {
+	actionWithDirection
+}
+
+func (a swapAction) Perform() error {
+	target := a.TargetActor()
+	if target == nil {
+		return impossible{"Nobody to swap places with."}
+	}
+
+	target.X, target.Y = a.Entity.X, a.Entity.Y
+	a.Entity.Move(a.Dx, a.Dy)
+
+	if a.Entity == a.Engine().Player {
+		a.Engine().MessageLog.AddMessage(fmt.Sprintf("You swap places with the %s.", target.Name), ColorWhite, true)
+		a.Engine().AutopickupItems()
+	}
 	return nil
 }
 
@@ -125,7 +343,19 @@ type bumpAction struct {
 }
 
 func (a bumpAction) Perform() error {
-	if a.TargetActor() != nil {
+	if target := a.TargetActor(); target != nil && a.Entity.IsAlly(target) {
+		return swapAction{
+			actionWithDirection{
+				baseAction: baseAction{
+					Entity: a.Entity,
+				},
+				Dx: a.Dx,
+				Dy: a.Dy,
+			},
+		}.Perform()
+	} else if target != nil && !a.Entity.IsHostile(target) {
+		return impossible{fmt.Sprintf("The %s is in the way.", target.Name)}
+	} else if target != nil {
 		return meleeAction{
 			actionWithDirection{
 				baseAction: baseAction{
@@ -150,6 +380,7 @@ func (a bumpAction) Perform() error {
 
 type pickupAction struct {
 	baseAction
+	Items []*item // picks up the first item on the tile when empty
 }
 
 func newPickupAction(entity *actor) *pickupAction {
@@ -160,40 +391,205 @@ func newPickupAction(entity *actor) *pickupAction {
 	}
 }
 
+func newPickupItemsAction(entity *actor, items []*item) *pickupAction {
+	return &pickupAction{
+		baseAction: baseAction{
+			Entity: entity,
+		},
+		Items: items,
+	}
+}
+
 func (a *pickupAction) Perform() error {
-	actorLocationX := a.Entity.X
-	actorLocationY := a.Entity.Y
-	inv := a.Entity.Inventory
+	items := a.Items
+	if len(items) == 0 {
+		items = a.Engine().GameMap.ItemsAt(a.Entity.X, a.Entity.Y)
+		if len(items) == 0 {
+			return impossible{"There is nothing here to pick up."}
+		}
+		items = items[:1]
+	}
 
-	for _, it := range a.Engine().GameMap.Items() {
-		if actorLocationX == it.X && actorLocationY == it.Y {
-			if len(inv.Items) >= inv.Capacity {
-				return impossible{"Your inventory is full."}
+	// Once something has been picked up the turn is spent, so later
+	// failures are only reported.
+	pickedUp := false
+	for _, it := range items {
+		if err := a.pickUp(it); err != nil {
+			if !pickedUp {
+				return err
 			}
-
-			entities := a.Engine().GameMap.Entities
-			for i, e := range entities {
-				if e == it {
-					entities = append(entities[:i], entities[i+1:]...)
-					break
-				}
+			if a.Entity == a.Engine().Player {
+				a.Engine().MessageLog.AddMessage(err.Error(), ColorImpossible, true)
 			}
-			a.Engine().GameMap.Entities = entities
+			break
+		}
+		pickedUp = true
+	}
+	return nil
+}
+
+func (a *pickupAction) pickUp(it *item) error {
+	inv := a.Entity.Inventory
+	if it.X != a.Entity.X || it.Y != a.Entity.Y || it.Parent != a.Engine().GameMap {
+		return impossible{"There is nothing here to pick up."}
+	}
+	if it.Gold == 0 && !inv.CanCarry(it) {
+		return impossible{fmt.Sprintf("The %s is too heavy to carry.", it.DisplayName())}
+	}
+	if it.Gold == 0 && !inv.Add(it) {
+		return impossible{"Your inventory is full."}
+	}
+
+	entities := a.Engine().GameMap.Entities
+	for i, e := range entities {
+		if e == it {
+			entities = append(entities[:i], entities[i+1:]...)
+			break
+		}
+	}
+	a.Engine().GameMap.Entities = entities
+
+	if it.Gold > 0 {
+		a.Entity.Gold += it.Gold
+	}
+	if it.Shop != nil {
+		if a.Entity == a.Engine().Player {
+			it.Shop.Rob(a.Entity)
+		}
+		it.Shop = nil
+	}
+
+	if a.Entity == a.Engine().Player {
+		a.Engine().MessageLog.AddMessage(fmt.Sprintf("You picked up the %s!", it.DisplayName()), ColorWhite, true)
+	} else if a.Engine().GameMap.Visible[a.Entity.X][a.Entity.Y] {
+		a.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s picks up the %s.", a.Entity.Name, it.DisplayName()), ColorWhite, true)
+	}
+	return nil
+}
+
+type buyAction struct {
+	baseAction
+	Shop *shop
+	Item *item
+}
+
+func newBuyAction(entity *actor, s *shop, it *item) *buyAction {
+	return &buyAction{
+		baseAction: baseAction{
+			Entity: entity,
+		},
+		Shop: s,
+		Item: it,
+	}
+}
 
-			it.Parent = a.Entity.Inventory
-			inv.Items = append(inv.Items, it)
+func (a *buyAction) Perform() error {
+	if !a.Shop.IsOpen() {
+		return impossible{"Nobody is minding the shop."}
+	}
+	price := a.Shop.BuyPrice(a.Item)
+	if a.Entity.Gold < price {
+		return impossible{fmt.Sprintf("You can't afford the %s.", a.Item.DisplayName())}
+	}
+	if !a.Entity.Inventory.CanCarry(a.Item) {
+		return impossible{fmt.Sprintf("The %s is too heavy to carry.", a.Item.DisplayName())}
+	}
+	if !a.Entity.Inventory.Add(a.Item) {
+		return impossible{"Your inventory is full."}
+	}
 
-			a.Engine().MessageLog.AddMessage(fmt.Sprintf("You picked up the %s!", it.Entity().Name), ColorWhite, true)
-			return nil
+	gm := a.Engine().GameMap
+	for i, e := range gm.Entities {
+		if e == a.Item {
+			gm.Entities = append(gm.Entities[:i], gm.Entities[i+1:]...)
+			break
 		}
 	}
-	return impossible{"There is nothing here to pick up."}
+	a.Item.Shop = nil
+	a.Entity.Gold -= price
+
+	a.Engine().MessageLog.AddMessage(fmt.Sprintf("You buy the %s for %d gold.", a.Item.DisplayName(), price), ColorWhite, true)
+	return nil
+}
+
+type sellAction struct {
+	baseAction
+	Shop *shop
+	Item *item
+}
+
+func newSellAction(entity *actor, s *shop, it *item) *sellAction {
+	return &sellAction{
+		baseAction: baseAction{
+			Entity: entity,
+		},
+		Shop: s,
+		Item: it,
+	}
+}
+
+func (a *sellAction) Perform() error {
+	if !a.Shop.IsOpen() {
+		return impossible{"Nobody is minding the shop."}
+	}
+	price := a.Shop.SellPrice(a.Item)
+
+	if a.Item.Quantity == 1 && a.Entity.Equipment.IsEquipped(a.Item) {
+		a.Entity.Equipment.Unequip(a.Item, true)
+	}
+	sold := a.Entity.Inventory.Take(a.Item)
+	a.Shop.Shelve(sold)
+	a.Entity.Gold += price
+
+	a.Engine().MessageLog.AddMessage(fmt.Sprintf("You sell the %s for %d gold.", sold.DisplayName(), price), ColorWhite, true)
+	return nil
+}
+
+// corpseNutrition is how much satiation each point of a corpse's maximum HP
+// is worth.
+const corpseNutrition = 20
+
+type eatCorpseAction struct {
+	baseAction
+}
+
+func newEatCorpseAction(entity *actor) *eatCorpseAction {
+	return &eatCorpseAction{
+		baseAction: baseAction{
+			Entity: entity,
+		},
+	}
+}
+
+func (a *eatCorpseAction) Perform() error {
+	gm := a.Engine().GameMap
+	hunger := a.Entity.Hunger
+	if hunger == nil {
+		return nil
+	}
+
+	for i, e := range gm.Entities {
+		corpse, ok := e.(*actor)
+		if !ok || corpse == a.Entity || corpse.IsAlive() || corpse.X != a.Entity.X || corpse.Y != a.Entity.Y {
+			continue
+		}
+		if hunger.Satiation >= hunger.MaxSatiation {
+			return impossible{"You are too full to eat."}
+		}
+
+		gm.Entities = append(gm.Entities[:i], gm.Entities[i+1:]...)
+		hunger.Eat(corpse.Fighter.MaxHP * corpseNutrition)
+		a.Engine().MessageLog.AddMessage(fmt.Sprintf("You eat the %s.", corpse.Name), ColorHealthRecovered, true)
+		return nil
+	}
+	return impossible{"There is nothing here to eat."}
 }
 
 type itemAction struct {
 	baseAction
-	Item     *item
-	TargetXY [2]int
+	Item       *item
+	TargetXY   [2]int
+	TargetItem *item // the inventory item picked for identify and the like
 }
 
 func newItemAction(entity *actor, i *item, targetXY *[2]int) *itemAction {
@@ -216,5 +612,16 @@ func (a *itemAction) TargetActor() *actor {
 }
 
 func (a *itemAction) Perform() error {
-	return a.Item.Consumable.Activate(a)
+	name := a.Item.DisplayName()
+	if a.Item.IsEmpty() {
+		return impossible{fmt.Sprintf("The %s is empty.", name)}
+	}
+	if err := a.Item.Consumable.Activate(a); err != nil {
+		return err
+	}
+	// Using an item is the surest way to find out what it is.
+	if a.Entity == a.Engine().Player && a.Engine().Knowledge.Identify(a.Item) {
+		a.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s turns out to be the %s.", name, a.Item.FullName()), ColorWhite, true)
+	}
+	return nil
 }
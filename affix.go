@@ -0,0 +1,211 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+type rarity int
+
+const (
+	rarityCommon rarity = iota
+	rarityMagic
+	rarityRare
+)
+
+func (r rarity) String() string {
+	switch r {
+	case rarityMagic:
+		return "magic"
+	case rarityRare:
+		return "rare"
+	default:
+		return "common"
+	}
+}
+
+func (r rarity) Color() color.RGBA {
+	switch r {
+	case rarityMagic:
+		return ColorRarityMagic
+	case rarityRare:
+		return ColorRarityRare
+	default:
+		return ColorWhite
+	}
+}
+
+// valueMultiplier is how much more shops ask for items of this rarity.
+func (r rarity) valueMultiplier() int {
+	switch r {
+	case rarityMagic:
+		return 2
+	case rarityRare:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// affix is a modifier rolled onto a generated item. Apply reports false,
+// leaving the item untouched, when the affix doesn't fit the item.
+type affix struct {
+	Name   string
+	Prefix bool
+	Apply  func(it *item) bool
+}
+
+func isWeapon(it *item) bool {
+	return it.Equippable != nil && it.Equippable.Slot == slotWeapon
+}
+
+func isArmor(it *item) bool {
+	return it.Equippable != nil && (it.Equippable.Slot == slotArmor || it.Equippable.Slot == slotShield)
+}
+
+var affixes = []affix{
+	{"Greater", true, func(it *item) bool {
+		switch c := it.Consumable.(type) {
+		case *healingConsumable:
+			c.Amount += c.Amount/2 + 2
+		case *statusEffectConsumable:
+			if !c.Kind.UsesMagnitude() {
+				return false
+			}
+			c.Magnitude += 1
+		default:
+			return false
+		}
+		return true
+	}},
+	{"Potent", true, func(it *item) bool {
+		switch c := it.Consumable.(type) {
+		case *lightningDamageConsumable:
+			c.Damage += 5
+		case *lightningBoltConsumable:
+			c.Damage += 4
+		case *fireballDamageConsumable:
+			c.Damage += 4
+		default:
+			return false
+		}
+		return true
+	}},
+	{"Sharp", true, func(it *item) bool {
+		if !isWeapon(it) {
+			return false
+		}
+		it.Equippable.PowerBonus += 1
+		return true
+	}},
+	{"Keen", true, func(it *item) bool {
+		if !isWeapon(it) || it.Equippable.Damage.IsZero() {
+			return false
+		}
+		it.Equippable.Damage.Bonus += 2
+		return true
+	}},
+	{"Sturdy", true, func(it *item) bool {
+		if !isArmor(it) {
+			return false
+		}
+		it.Equippable.DefenseBonus += 1
+		return true
+	}},
+	{"of Widening", false, func(it *item) bool {
+		c, ok := it.Consumable.(*fireballDamageConsumable)
+		if !ok {
+			return false
+		}
+		c.Radius += 1
+		return true
+	}},
+	{"of Reach", false, func(it *item) bool {
+		switch c := it.Consumable.(type) {
+		case *lightningDamageConsumable:
+			c.MaximumRange += 3
+		case *lightningBoltConsumable:
+			c.MaximumRange += 3
+		default:
+			return false
+		}
+		return true
+	}},
+	{"of Lingering", false, func(it *item) bool {
+		switch c := it.Consumable.(type) {
+		case *statusEffectConsumable:
+			c.Turns += c.Turns / 2
+		case *targetedEffectConsumable:
+			c.Turns += c.Turns / 2
+		case *confusionConsumable:
+			c.NumberOfTurns += c.NumberOfTurns / 2
+		default:
+			return false
+		}
+		return true
+	}},
+	{"of Nourishment", false, func(it *item) bool {
+		c, ok := it.Consumable.(*foodConsumable)
+		if !ok {
+			return false
+		}
+		c.Nutrition += c.Nutrition / 2
+		return true
+	}},
+	{"of Plenty", false, func(it *item) bool {
+		if it.MaxCharges == 0 {
+			return false
+		}
+		it.MaxCharges += 2
+		it.Charges += 2
+		return true
+	}},
+	{"of Power", false, func(it *item) bool {
+		if it.Equippable == nil || it.Equippable.AmmoKind != ammoNone {
+			return false
+		}
+		it.Equippable.PowerBonus += 1
+		return true
+	}},
+	{"of Warding", false, func(it *item) bool {
+		if it.Equippable == nil || it.Equippable.Slot == slotWeapon || it.Equippable.Slot == slotQuiver {
+			return false
+		}
+		it.Equippable.DefenseBonus += 1
+		return true
+	}},
+}
+
+// rollRarity makes one in four generated items magic, with a single affix,
+// and one in twenty rare, with a prefix and a suffix. Items no affix fits
+// stay common.
+func rollRarity(it *item) {
+	wanted := 0
+	switch r := rand.Intn(20); {
+	case r == 0:
+		wanted = 2
+	case r < 6:
+		wanted = 1
+	}
+
+	applied := 0
+	for _, i := range rand.Perm(len(affixes)) {
+		if applied == wanted {
+			break
+		}
+		a := affixes[i]
+		if (a.Prefix && it.Prefix != "") || (!a.Prefix && it.Suffix != "") {
+			continue
+		}
+		if !a.Apply(it) {
+			continue
+		}
+		if a.Prefix {
+			it.Prefix = a.Name
+		} else {
+			it.Suffix = a.Name
+		}
+		applied += 1
+	}
+	it.Rarity = rarity(applied)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"container/heap"
 	"fmt"
 	"math"
 	"math/rand"
@@ -16,60 +17,171 @@ type baseAI struct {
 	Entity *actor
 }
 
-type confusedEnemy struct {
+// nearestHostile returns the closest actor the entity considers hostile and
+// can see, or nil when there is none.
+func (ai *baseAI) nearestHostile() *actor {
+	var target *actor
+	closestDistance := math.Inf(1)
+	for _, a := range ai.Engine().GameMap.Actors() {
+		if !ai.Entity.IsHostile(a) || !ai.Entity.CanSee(a) {
+			continue
+		}
+		if distance := ai.Entity.Distance(a.X, a.Y); distance < closestDistance {
+			target = a
+			closestDistance = distance
+		}
+	}
+	return target
+}
+
+// chooseItemAction looks through the entity's inventory for something worth
+// using against target this turn: a healing potion when badly hurt or an
+// offensive scroll. With no target around it picks up whatever lies at its
+// feet. It returns nil when there is nothing to do with items.
+func (ai *baseAI) chooseItemAction(target *actor) action {
+	inv := ai.Entity.Inventory
+	fig := ai.Entity.Fighter
+
+	if fig.HP*2 < fig.MaxHP {
+		for _, it := range inv.Items {
+			if _, ok := it.Consumable.(*healingConsumable); ok {
+				return newItemAction(ai.Entity, it, nil)
+			}
+		}
+	}
+
+	if target != nil {
+		targetXY := &[2]int{target.X, target.Y}
+		distance := ai.Entity.Distance(target.X, target.Y)
+		for _, it := range inv.Items {
+			if it.IsEmpty() {
+				continue
+			}
+			switch c := it.Consumable.(type) {
+			case *lightningDamageConsumable:
+				if distance <= float64(c.MaximumRange) {
+					return newItemAction(ai.Entity, it, nil)
+				}
+			case *lightningBoltConsumable:
+				if distance <= float64(c.MaximumRange) {
+					return newItemAction(ai.Entity, it, targetXY)
+				}
+			case *targetedEffectConsumable:
+				if !target.StatusEffects.Has(c.Kind) {
+					return newItemAction(ai.Entity, it, targetXY)
+				}
+			case *fireballDamageConsumable:
+				// Don't get caught in the blast.
+				if !ai.Engine().GameMap.BlastArea(target.X, target.Y, c.Radius)[[2]int{ai.Entity.X, ai.Entity.Y}] {
+					return newItemAction(ai.Entity, it, targetXY)
+				}
+			case *confusionConsumable:
+				if !target.StatusEffects.Has(effectConfusion) {
+					return newItemAction(ai.Entity, it, targetXY)
+				}
+			}
+		}
+		return nil
+	}
+
+	if len(inv.Items) < inv.Capacity {
+		for _, it := range ai.Engine().GameMap.Items() {
+			if it.X == ai.Entity.X && it.Y == ai.Entity.Y && inv.CanCarry(it) {
+				return newPickupAction(ai.Entity)
+			}
+		}
+	}
+	return nil
+}
+
+// stumble moves entity in a random direction, attacking whatever it bumps into.
+func stumble(entity *actor) error {
+	d := directions[rand.Intn(len(directions))]
+	return bumpAction{
+		actionWithDirection{
+			baseAction: baseAction{
+				Entity: entity,
+			},
+			Dx: d[0],
+			Dy: d[1],
+		},
+	}.Perform()
+}
+
+type dormantEnemy struct {
 	baseAI
-	PreviousAI     AI
-	TurnsRemaining int
+	PreviousAI AI
+	Asleep     bool
+	Alertness  int // percent chance to notice an enemy in sight each turn
 }
 
-func newConfusedEnemy(entity *actor, previousAI AI, turnsRemaining int) *confusedEnemy {
-	return &confusedEnemy{
+func newDormantEnemy(entity *actor, previousAI AI, alertness int) *dormantEnemy {
+	return &dormantEnemy{
 		baseAI: baseAI{
 			Entity: entity,
 			baseComponent: baseComponent{
 				Parent: entity,
 			},
 		},
-		PreviousAI:     previousAI,
-		TurnsRemaining: turnsRemaining,
+		PreviousAI: previousAI,
+		Asleep:     rand.Float32() < 0.5,
+		Alertness:  alertness,
 	}
 }
 
-func (ai *confusedEnemy) Perform() error {
-	if ai.TurnsRemaining <= 0 {
-		ai.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s is no logner confused.", ai.Entity.Name), ColorWhite, true)
-		ai.Entity.AI = ai.PreviousAI
-	} else {
-		direction := [][2]int{
-			{-1, -1}, // Northwest
-			{0, -1},  // North
-			{1, -1},  // Northeast
-			{-1, 0},  // West
-			{1, 0},   // East
-			{-1, 1},  // Southwest
-			{0, 1},   // South
-			{1, 1},   // Southwest
-		}
-		d := direction[rand.Intn(len(direction)-1)]
+func (ai *dormantEnemy) State() string {
+	if ai.Asleep {
+		return "asleep"
+	}
+	return "unaware"
+}
 
-		ai.TurnsRemaining -= 1
+func (ai *dormantEnemy) Perform() error {
+	target := ai.nearestHostile()
+	if target == nil {
+		return waitAction{}.Perform()
+	}
 
-		return bumpAction{
-			actionWithDirection{
-				baseAction: baseAction{
-					Entity: ai.Entity,
-				},
-				Dx: d[0],
-				Dy: d[1],
-			},
-		}.Perform()
+	distance := math.Max(math.Abs(float64(target.X-ai.Entity.X)), math.Abs(float64(target.Y-ai.Entity.Y)))
+	if distance <= 1 || !ai.stealthCheck(distance) {
+		ai.Wake()
 	}
-	return nil
+	return waitAction{}.Perform()
+}
+
+// stealthCheck reports whether an enemy at the given distance stays
+// unnoticed this turn. Sleepers are four times less likely to notice.
+func (ai *dormantEnemy) stealthCheck(distance float64) bool {
+	chance := ai.Alertness - int(distance)*5
+	if ai.Asleep {
+		chance /= 4
+	}
+	return rand.Intn(100) >= chance
 }
 
+func (ai *dormantEnemy) Wake() {
+	if ai.Engine().GameMap.Visible[ai.Entity.X][ai.Entity.Y] {
+		if ai.Asleep {
+			ai.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s wakes up!", ai.Entity.Name), ColorWhite, true)
+		} else {
+			ai.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s notices you!", ai.Entity.Name), ColorWhite, true)
+		}
+	}
+	ai.Entity.AI = ai.PreviousAI
+}
+
+const (
+	searchTurns  = 10
+	searchRadius = 4
+	wanderRadius = 20
+)
+
 type hostileEnemy struct {
 	baseAI
-	Path [][2]int
+	Path          [][2]int
+	FleeThreshold float64 // fraction of MaxHP below which the enemy flees, 0 never flees
+	LastKnown     *[2]int // where the target was last seen, nil once the enemy gave up searching
+	SearchTurns   int
 }
 
 func NewHostileEnemy(entity *actor) *hostileEnemy {
@@ -84,18 +196,325 @@ func NewHostileEnemy(entity *actor) *hostileEnemy {
 	}
 }
 
-func (ai hostileEnemy) getPathTo(destX, destY int) [][2]int {
+func (ai *hostileEnemy) getPathTo(destX, destY int) [][2]int {
 	return aster(ai.Entity.Parent.(*gameMap).Tiles, [2]int{ai.Entity.X, ai.Entity.Y}, [2]int{destX, destY})
 }
 
-func (ai hostileEnemy) Perform() error {
-	target := ai.Engine().Player
+func (ai *hostileEnemy) Perform() error {
+	target := ai.nearestHostile()
+	if act := ai.chooseItemAction(target); act != nil {
+		return act.Perform()
+	}
+
+	if ai.ShouldFlee() {
+		if ai.Engine().GameMap.Visible[ai.Entity.X][ai.Entity.Y] {
+			ai.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s flees!", ai.Entity.Name), ColorWhite, true)
+		}
+		fleeing := newFleeingEnemy(ai.Entity, ai.Entity.AI)
+		ai.Entity.AI = fleeing
+		return fleeing.Perform()
+	}
+
+	if target != nil {
+		dx := target.X - ai.Entity.X
+		dy := target.Y - ai.Entity.Y
+		distance := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+		if distance <= 1 {
+			return meleeAction{
+				actionWithDirection{
+					baseAction: baseAction{
+						Entity: ai.Entity,
+					},
+					Dx: dx,
+					Dy: dy,
+				},
+			}.Perform()
+		}
+
+		ai.LastKnown = &[2]int{target.X, target.Y}
+		ai.SearchTurns = searchTurns
+		ai.Path = ai.getPathTo(target.X, target.Y)
+	} else if ai.LastKnown != nil {
+		if len(ai.Path) == 0 {
+			// Arrived where the target was last seen, look around for a while.
+			if ai.SearchTurns <= 0 {
+				ai.LastKnown = nil
+			} else if dest, ok := randomReachableTile(ai.Engine().GameMap, *ai.LastKnown, searchRadius); ok {
+				ai.Path = ai.getPathTo(dest[0], dest[1])
+			}
+		}
+		ai.SearchTurns -= 1
+	} else if len(ai.Path) == 0 {
+		if dest, ok := randomReachableTile(ai.Engine().GameMap, [2]int{ai.Entity.X, ai.Entity.Y}, wanderRadius); ok {
+			ai.Path = ai.getPathTo(dest[0], dest[1])
+		}
+	}
+
+	if len(ai.Path) > 0 {
+		destX, destY := ai.Path[0][0], ai.Path[0][1]
+		if err := (movementAction{
+			actionWithDirection{
+				baseAction: baseAction{
+					Entity: ai.Entity,
+				},
+				Dx: destX - ai.Entity.X,
+				Dy: destY - ai.Entity.Y,
+			},
+		}).Perform(); err != nil {
+			// Something is in the way, pick a new route next turn.
+			ai.Path = [][2]int{}
+			return err
+		}
+		ai.Path = ai.Path[1:]
+		return nil
+	}
+
+	return waitAction{}.Perform()
+}
+
+// randomReachableTile picks a random walkable tile that can be reached from
+// the origin within maxDistance steps.
+func randomReachableTile(gm *gameMap, origin [2]int, maxDistance float64) ([2]int, bool) {
+	dm := dijkstraMap(gm, [][2]int{origin})
+	candidates := [][2]int{}
+	for w := range dm {
+		for h := range dm[w] {
+			if dm[w][h] > 0 && dm[w][h] <= maxDistance {
+				candidates = append(candidates, [2]int{w, h})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return [2]int{}, false
+	}
+	return candidates[rand.Intn(len(candidates))], true
+}
+
+func (ai *hostileEnemy) ShouldFlee() bool {
+	fig := ai.Entity.Fighter
+	return float64(fig.HP) < float64(fig.MaxHP)*ai.FleeThreshold
+}
+
+const fleeRegenInterval = 3
+
+type fleeingEnemy struct {
+	baseAI
+	PreviousAI      AI
+	TurnsOutOfSight int
+	Cornered        bool
+}
+
+func newFleeingEnemy(entity *actor, previousAI AI) *fleeingEnemy {
+	return &fleeingEnemy{
+		baseAI: baseAI{
+			Entity: entity,
+			baseComponent: baseComponent{
+				Parent: entity,
+			},
+		},
+		PreviousAI: previousAI,
+	}
+}
+
+func (ai *fleeingEnemy) Perform() error {
+	fig := ai.Entity.Fighter
+
+	if fig.HP >= fig.MaxHP {
+		if ai.Engine().GameMap.Visible[ai.Entity.X][ai.Entity.Y] {
+			ai.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s regroups and returns to the fight!", ai.Entity.Name), ColorWhite, true)
+		}
+		ai.Entity.AI = ai.PreviousAI
+		return ai.PreviousAI.Perform()
+	}
+
+	target := ai.nearestHostile()
+	if act := ai.chooseItemAction(target); act != nil {
+		return act.Perform()
+	}
+	if target == nil {
+		// Out of sight of its enemies the enemy catches its breath.
+		ai.TurnsOutOfSight += 1
+		if ai.TurnsOutOfSight%fleeRegenInterval == 0 {
+			fig.Heal(1)
+		}
+		return waitAction{}.Perform()
+	}
+	ai.TurnsOutOfSight = 0
+
+	if dest, ok := fleeStep(ai.Entity, target); ok {
+		ai.Cornered = false
+		return movementAction{
+			actionWithDirection{
+				baseAction: baseAction{
+					Entity: ai.Entity,
+				},
+				Dx: dest[0] - ai.Entity.X,
+				Dy: dest[1] - ai.Entity.Y,
+			},
+		}.Perform()
+	}
+
 	dx := target.X - ai.Entity.X
 	dy := target.Y - ai.Entity.Y
-	distance := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+	if int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy)))) > 1 {
+		return waitAction{}.Perform()
+	}
+	if !ai.Cornered {
+		ai.Cornered = true
+		ai.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s is cornered!", ai.Entity.Name), ColorWhite, true)
+	}
+	return meleeAction{
+		actionWithDirection{
+			baseAction: baseAction{
+				Entity: ai.Entity,
+			},
+			Dx: dx,
+			Dy: dy,
+		},
+	}.Perform()
+}
 
-	if ai.Engine().GameMap.Visible[ai.Entity.X][ai.Entity.Y] {
+// shopkeeper minds its shop and doesn't leave it. Robbing the shop replaces
+// it with a hostileEnemy.
+type shopkeeper struct {
+	baseAI
+	Shop *shop
+}
+
+func newShopkeeper(entity *actor) *shopkeeper {
+	return &shopkeeper{
+		baseAI: baseAI{
+			Entity: entity,
+			baseComponent: baseComponent{
+				Parent: entity,
+			},
+		},
+	}
+}
+
+func (ai *shopkeeper) Perform() error {
+	return waitAction{}.Perform()
+}
+
+type alliedCompanion struct {
+	baseAI
+	FollowDistance int
+}
+
+func newAlliedCompanion(entity *actor, followDistance int) *alliedCompanion {
+	return &alliedCompanion{
+		baseAI: baseAI{
+			Entity: entity,
+			baseComponent: baseComponent{
+				Parent: entity,
+			},
+		},
+		FollowDistance: followDistance,
+	}
+}
+
+func (ai *alliedCompanion) Perform() error {
+	gm := ai.Engine().GameMap
+	leader := ai.Engine().Player
+
+	// Defend the leader against any enemy standing next to it, or next to us.
+	var target *actor
+	for _, a := range gm.Actors() {
+		if !ai.Entity.IsHostile(a) || !gm.Visible[a.X][a.Y] {
+			continue
+		}
+		nearLeader := math.Max(math.Abs(float64(a.X-leader.X)), math.Abs(float64(a.Y-leader.Y))) <= 1
+		nearSelf := math.Max(math.Abs(float64(a.X-ai.Entity.X)), math.Abs(float64(a.Y-ai.Entity.Y))) <= 1
+		if nearSelf {
+			target = a
+			break
+		}
+		if nearLeader && target == nil {
+			target = a
+		}
+	}
+
+	goal := leader
+	if target != nil {
+		dx := target.X - ai.Entity.X
+		dy := target.Y - ai.Entity.Y
+		if math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))) <= 1 {
+			return meleeAction{
+				actionWithDirection{
+					baseAction: baseAction{
+						Entity: ai.Entity,
+					},
+					Dx: dx,
+					Dy: dy,
+				},
+			}.Perform()
+		}
+		goal = target
+	} else if ai.Entity.Distance(leader.X, leader.Y) <= float64(ai.FollowDistance) {
+		return waitAction{}.Perform()
+	}
+
+	path := aster(gm.Tiles, [2]int{ai.Entity.X, ai.Entity.Y}, [2]int{goal.X, goal.Y})
+	if len(path) == 0 {
+		return waitAction{}.Perform()
+	}
+	return movementAction{
+		actionWithDirection{
+			baseAction: baseAction{
+				Entity: ai.Entity,
+			},
+			Dx: path[0][0] - ai.Entity.X,
+			Dy: path[0][1] - ai.Entity.Y,
+		},
+	}.Perform()
+}
+
+type rangedEnemy struct {
+	baseAI
+	Path       [][2]int
+	Range      int
+	Projectile string
+	DamageType damageType
+}
+
+func newRangedEnemy(entity *actor, maximumRange int, projectile string, dt damageType) *rangedEnemy {
+	return &rangedEnemy{
+		baseAI: baseAI{
+			Entity: entity,
+			baseComponent: baseComponent{
+				Parent: entity,
+			},
+		},
+		Path:       [][2]int{},
+		Range:      maximumRange,
+		Projectile: projectile,
+		DamageType: dt,
+	}
+}
+
+func (ai *rangedEnemy) Perform() error {
+	target := ai.nearestHostile()
+	if act := ai.chooseItemAction(target); act != nil {
+		return act.Perform()
+	}
+
+	if target != nil {
+		dx := target.X - ai.Entity.X
+		dy := target.Y - ai.Entity.Y
+		distance := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
 		if distance <= 1 {
+			// Back off to get some room to shoot, fight only when there is no way out.
+			if dest, ok := fleeStep(ai.Entity, target); ok {
+				return movementAction{
+					actionWithDirection{
+						baseAction: baseAction{
+							Entity: ai.Entity,
+						},
+						Dx: dest[0] - ai.Entity.X,
+						Dy: dest[1] - ai.Entity.Y,
+					},
+				}.Perform()
+			}
 			return meleeAction{
 				actionWithDirection{
 					baseAction: baseAction{
@@ -107,11 +526,25 @@ func (ai hostileEnemy) Perform() error {
 			}.Perform()
 		}
 
-		ai.Path = ai.getPathTo(target.X, target.Y)
+		if distance <= ai.Range {
+			if _, hit := projectilePath(ai.Engine().GameMap, ai.Entity.X, ai.Entity.Y, target.X, target.Y); hit == target {
+				return rangedAttackAction{
+					baseAction: baseAction{
+						Entity: ai.Entity,
+					},
+					TargetXY:   [2]int{target.X, target.Y},
+					Projectile: ai.Projectile,
+					DamageType: ai.DamageType,
+				}.Perform()
+			}
+		}
+
+		ai.Path = aster(ai.Entity.Parent.(*gameMap).Tiles, [2]int{ai.Entity.X, ai.Entity.Y}, [2]int{target.X, target.Y})
 	}
 
 	if len(ai.Path) > 0 {
 		destX, destY := ai.Path[0][0], ai.Path[0][1]
+		ai.Path = ai.Path[1:]
 		return movementAction{
 			actionWithDirection{
 				baseAction: baseAction{
@@ -126,6 +559,119 @@ func (ai hostileEnemy) Perform() error {
 	return waitAction{}.Perform()
 }
 
+// fleeStep picks the neighbouring tile that leads furthest away from the
+// threat according to a flee map. It returns false when there is no better
+// tile to go to, in which case the entity is cornered.
+func fleeStep(entity *actor, threat *actor) ([2]int, bool) {
+	gm := entity.GameMap()
+	fm := fleeMap(gm, [][2]int{{threat.X, threat.Y}})
+
+	best := fm[entity.X][entity.Y]
+	dest := [2]int{entity.X, entity.Y}
+	found := false
+	for _, d := range directions {
+		x, y := entity.X+d[0], entity.Y+d[1]
+		if !gm.InBounds(x, y) || !gm.Walkable(x, y) || gm.GetBlockingEntityAtLocation(x, y) != nil {
+			continue
+		}
+		if fm[x][y] < best {
+			best = fm[x][y]
+			dest = [2]int{x, y}
+			found = true
+		}
+	}
+	return dest, found
+}
+
+var directions = [][2]int{
+	{-1, -1},
+	{0, -1},
+	{1, -1},
+	{-1, 0},
+	{1, 0},
+	{-1, 1},
+	{0, 1},
+	{1, 1},
+}
+
+// dijkstraMap returns the walking distance from every tile to the nearest
+// goal. Unreachable tiles are +Inf.
+func dijkstraMap(gm *gameMap, goals [][2]int) [][]float64 {
+	dm := make([][]float64, gm.Width)
+	for w := range dm {
+		dm[w] = make([]float64, gm.Height)
+		for h := range dm[w] {
+			dm[w][h] = math.Inf(1)
+		}
+	}
+	for _, g := range goals {
+		dm[g[0]][g[1]] = 0
+	}
+	relaxDijkstraMap(gm, dm)
+	return dm
+}
+
+// fleeMap inverts a Dijkstra map towards the threats and rescans it, so that
+// the lowest values lie along escape routes rather than in the nearest dead end.
+// http://www.roguebasin.com/index.php/The_Incredible_Power_of_Dijkstra_Maps
+func fleeMap(gm *gameMap, threats [][2]int) [][]float64 {
+	dm := dijkstraMap(gm, threats)
+	for w := range dm {
+		for h := range dm[w] {
+			if !math.IsInf(dm[w][h], 1) {
+				dm[w][h] *= -1.2
+			}
+		}
+	}
+	relaxDijkstraMap(gm, dm)
+	return dm
+}
+
+func relaxDijkstraMap(gm *gameMap, dm [][]float64) {
+	pq := &dijkstraQueue{}
+	for w := range dm {
+		for h := range dm[w] {
+			if !math.IsInf(dm[w][h], 1) {
+				heap.Push(pq, dijkstraNode{Position: [2]int{w, h}, Value: dm[w][h]})
+			}
+		}
+	}
+	for pq.Len() > 0 {
+		n := heap.Pop(pq).(dijkstraNode)
+		if n.Value > dm[n.Position[0]][n.Position[1]] {
+			continue
+		}
+		for _, d := range directions {
+			x, y := n.Position[0]+d[0], n.Position[1]+d[1]
+			if !gm.InBounds(x, y) || !gm.Walkable(x, y) {
+				continue
+			}
+			if n.Value+1 < dm[x][y] {
+				dm[x][y] = n.Value + 1
+				heap.Push(pq, dijkstraNode{Position: [2]int{x, y}, Value: dm[x][y]})
+			}
+		}
+	}
+}
+
+type dijkstraNode struct {
+	Position [2]int
+	Value    float64
+}
+
+type dijkstraQueue []dijkstraNode
+
+func (q dijkstraQueue) Len() int            { return len(q) }
+func (q dijkstraQueue) Less(i, j int) bool  { return q[i].Value < q[j].Value }
+func (q dijkstraQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *dijkstraQueue) Push(x interface{}) { *q = append(*q, x.(dijkstraNode)) }
+func (q *dijkstraQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
 type node struct {
 	Parent   *node
 	Position [2]int
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// autopickupRules are the kinds of items the player can choose to grab just
+// by walking over them.
+var autopickupRules = map[string]func(it *item) bool{
+	"gold":    func(it *item) bool { return it.Gold > 0 },
+	"potions": func(it *item) bool { return it.Category == categoryPotion },
+	"scrolls": func(it *item) bool { return it.Category == categoryScroll },
+	"wands":   func(it *item) bool { return it.Category == categoryWand },
+	"food":    func(it *item) bool { _, ok := it.Consumable.(*foodConsumable); return ok },
+	"ammo":    func(it *item) bool { return it.Equippable != nil && it.Equippable.AmmoKind != ammoNone },
+}
+
+// parseAutopickup reads a comma separated list of rule names such as
+// "gold,potions". An empty list turns autopickup off.
+func parseAutopickup(s string) ([]string, error) {
+	rules := []string{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := autopickupRules[name]; !ok {
+			return nil, fmt.Errorf("unknown autopickup rule: %s", name)
+		}
+		rules = append(rules, name)
+	}
+	return rules, nil
+}
+
+func (e *engine) WantsAutopickup(it *item) bool {
+	// Walking through a shop shouldn't rob it.
+	if it.Shop != nil {
+		return false
+	}
+	for _, name := range e.Autopickup {
+		if autopickupRules[name](it) {
+			return true
+		}
+	}
+	return false
+}
+
+// AutopickupItems picks up whatever the player's rules ask for on the tile
+// the player just stepped onto.
+func (e *engine) AutopickupItems() {
+	items := []*item{}
+	for _, it := range e.GameMap.ItemsAt(e.Player.X, e.Player.Y) {
+		if e.WantsAutopickup(it) {
+			items = append(items, it)
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+	if err := newPickupItemsAction(e.Player, items).Perform(); err != nil {
+		e.MessageLog.AddMessage(err.Error(), ColorImpossible, true)
+	}
+}
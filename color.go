@@ -30,4 +30,8 @@ var (
 	ColorMenuText  = ColorWhite
 
 	ColorSelect = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x88}
+	ColorBlast  = color.RGBA{R: 0xFF, G: 0x3F, B: 0x00, A: 0x66}
+
+	ColorRarityMagic = color.RGBA{R: 0x60, G: 0x90, B: 0xFF, A: 0xFF}
+	ColorRarityRare  = color.RGBA{R: 0xFF, G: 0xD0, B: 0x30, A: 0xFF}
 )
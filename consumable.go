@@ -3,12 +3,17 @@ package main
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"strings"
 )
 
 type consumable interface {
 	SetParent(i *item)
 	GetAction(consumer *actor) action
 	Activate(action *itemAction) error
+	Copy() consumable
+	Describe() []string
 }
 
 type baseConsumable struct {
@@ -27,8 +32,23 @@ func (c *baseConsumable) Activate(action *itemAction) error {
 	return errors.New("Not implemented error")
 }
 
+func (c *baseConsumable) Describe() []string {
+	return nil
+}
+
+// Consume uses up one item of the stack and removes the entry from the
+// inventory once the last one is gone. Wands spend a charge instead and are
+// kept even when empty.
 func (c *baseConsumable) Consume() {
 	entity := c.Parent
+	if it, ok := entity.(*item); ok && it.MaxCharges > 0 {
+		it.Charges -= 1
+		return
+	}
+	if it, ok := entity.(*item); ok && it.Quantity > 1 {
+		it.Quantity -= 1
+		return
+	}
 	inv := entity.Entity().Parent
 	if di, ok := inv.(*inventory); ok {
 		items := di.Items
@@ -58,12 +78,25 @@ func newHealingConsumable(amount int) *healingConsumable {
 	}
 }
 
+func (c *healingConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *healingConsumable) Describe() []string {
+	return []string{fmt.Sprintf("Heals %d HP.", c.Amount)}
+}
+
 func (c *healingConsumable) Activate(act *itemAction) error {
 	consumer := act.Entity
 	amountRecoverd := consumer.Fighter.Heal(c.Amount)
 
 	if amountRecoverd > 0 {
-		c.Engine().MessageLog.AddMessage(fmt.Sprintf("You consume the %s, and recover %d HP!", c.Parent.Entity().Name, amountRecoverd), ColorHealthRecovered, true)
+		if consumer == c.Engine().Player {
+			c.Engine().MessageLog.AddMessage(fmt.Sprintf("You consume the %s, and recover %d HP!", c.Parent.(*item).FullName(), amountRecoverd), ColorHealthRecovered, true)
+		} else if c.Engine().GameMap.Visible[consumer.X][consumer.Y] {
+			c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s consumes the %s, and recovers %d HP!", consumer.Name, c.Parent.(*item).DisplayName(), amountRecoverd), ColorWhite, true)
+		}
 		c.Consume()
 	} else {
 		return impossible{"Your health is already full."}
@@ -71,6 +104,81 @@ func (c *healingConsumable) Activate(act *itemAction) error {
 	return nil
 }
 
+type foodConsumable struct {
+	baseConsumable
+	Nutrition int
+}
+
+func newFoodConsumable(nutrition int) *foodConsumable {
+	return &foodConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+		Nutrition: nutrition,
+	}
+}
+
+func (c *foodConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *foodConsumable) Describe() []string {
+	return []string{fmt.Sprintf("Nutrition: %d.", c.Nutrition)}
+}
+
+func (c *foodConsumable) Activate(act *itemAction) error {
+	consumer := act.Entity
+	if consumer.Hunger == nil {
+		return impossible{fmt.Sprintf("The %s has no appetite.", consumer.Name)}
+	}
+	if consumer.Hunger.Satiation >= consumer.Hunger.MaxSatiation {
+		return impossible{"You are too full to eat."}
+	}
+
+	consumer.Hunger.Eat(c.Nutrition)
+	c.Engine().MessageLog.AddMessage(fmt.Sprintf("You eat the %s.", c.Parent.(*item).DisplayName()), ColorHealthRecovered, true)
+	c.Consume()
+	return nil
+}
+
+type statusEffectConsumable struct {
+	baseConsumable
+	Kind      effectKind
+	Turns     int
+	Magnitude int
+}
+
+func newStatusEffectConsumable(kind effectKind, turns, magnitude int) *statusEffectConsumable {
+	return &statusEffectConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+		Kind:      kind,
+		Turns:     turns,
+		Magnitude: magnitude,
+	}
+}
+
+func (c *statusEffectConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *statusEffectConsumable) Describe() []string {
+	return []string{fmt.Sprintf("Makes you %s for %d turns.", strings.ToLower(effectDefinitions[c.Kind].Name), c.Turns)}
+}
+
+func (c *statusEffectConsumable) Activate(act *itemAction) error {
+	act.Entity.StatusEffects.Apply(c.Kind, c.Turns, c.Magnitude)
+	c.Consume()
+	return nil
+}
+
 type lightningDamageConsumable struct {
 	baseConsumable
 	Damage       int
@@ -89,13 +197,25 @@ func newLightningDamageConsumable(damage, maximumRange int) *lightningDamageCons
 	}
 }
 
+func (c *lightningDamageConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *lightningDamageConsumable) Describe() []string {
+	return []string{
+		fmt.Sprintf("Strikes the nearest enemy for %d lightning damage.", c.Damage),
+		fmt.Sprintf("Range: %d", c.MaximumRange),
+	}
+}
+
 func (c *lightningDamageConsumable) Activate(act *itemAction) error {
 	consumer := act.Entity
 	var target *actor
 
 	closestDistance := float64(c.MaximumRange + 1.0)
 	for _, a := range c.Engine().GameMap.Actors() {
-		if a != consumer && c.Parent.GameMap().Visible[a.X][a.Y] {
+		if consumer.IsHostile(a) && consumer.CanSee(a) {
 			distance := consumer.Distance(a.X, a.Y)
 			if distance < closestDistance {
 				target = a
@@ -104,8 +224,9 @@ func (c *lightningDamageConsumable) Activate(act *itemAction) error {
 		}
 	}
 	if target != nil {
-		c.Engine().MessageLog.AddMessage(fmt.Sprintf("A lightning bolt strikes the %s with a loud thunder, for %d damage!", target.Name, c.Damage), ColorWhite, true)
-		target.Fighter.TakeDamage(c.Damage)
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("A lightning bolt strikes the %s with a loud thunder, for %d damage!", target.Name, target.Fighter.Resist(c.Damage, damageLightning)), ColorWhite, true)
+		c.Engine().MakeNoise(target.X, target.Y, noiseExplosion)
+		target.Fighter.TakeDamage(c.Damage, damageLightning, consumer)
 		c.Consume()
 	} else {
 		return impossible{"No enemy is close enough to strike."}
@@ -113,6 +234,198 @@ func (c *lightningDamageConsumable) Activate(act *itemAction) error {
 	return nil
 }
 
+type lightningBoltConsumable struct {
+	baseConsumable
+	Damage       int
+	MaximumRange int
+}
+
+func newLightningBoltConsumable(damage, maximumRange int) *lightningBoltConsumable {
+	return &lightningBoltConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+		Damage:       damage,
+		MaximumRange: maximumRange,
+	}
+}
+
+func (c *lightningBoltConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *lightningBoltConsumable) Describe() []string {
+	return []string{
+		fmt.Sprintf("Shoots a bolt dealing %d lightning damage.", c.Damage),
+		fmt.Sprintf("Range: %d", c.MaximumRange),
+	}
+}
+
+func (c *lightningBoltConsumable) GetAction(consumer *actor) action {
+	c.Engine().MessageLog.AddMessage("Select a target location.", ColorNeedsTarget, true)
+	return newSingleRangedAttackHandler(c.Engine(), true, func(x, y int) action {
+		return newItemAction(consumer, c.Parent.(*item), &[2]int{x, y})
+	})
+}
+
+// Activate shoots a bolt at the target location that strikes the first actor
+// in its way.
+func (c *lightningBoltConsumable) Activate(action *itemAction) error {
+	consumer := action.Entity
+	gm := c.Engine().GameMap
+	targetXY := action.TargetXY
+
+	if consumer == c.Engine().Player && !gm.Visible[targetXY[0]][targetXY[1]] {
+		return impossible{"You cannot target an area that you cannot see."}
+	} else if action.TargetActor() == nil || action.TargetActor() == consumer {
+		return impossible{"You must select an enemy to target."}
+	} else if consumer.Distance(targetXY[0], targetXY[1]) > float64(c.MaximumRange) {
+		return impossible{"The target is too far away."}
+	}
+
+	_, target := projectilePath(gm, consumer.X, consumer.Y, targetXY[0], targetXY[1])
+	if target == nil {
+		c.Engine().MessageLog.AddMessage("The lightning bolt strikes the wall.", ColorWhite, true)
+	} else {
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("A lightning bolt strikes the %s, for %d damage!", target.Name, target.Fighter.Resist(c.Damage, damageLightning)), ColorWhite, true)
+		target.Fighter.TakeDamage(c.Damage, damageLightning, consumer)
+	}
+	c.Engine().MakeNoise(targetXY[0], targetXY[1], noiseExplosion)
+
+	c.Consume()
+	return nil
+}
+
+type targetedEffectConsumable struct {
+	baseConsumable
+	Kind      effectKind
+	Turns     int
+	Magnitude int
+}
+
+func newTargetedEffectConsumable(kind effectKind, turns, magnitude int) *targetedEffectConsumable {
+	return &targetedEffectConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+		Kind:      kind,
+		Turns:     turns,
+		Magnitude: magnitude,
+	}
+}
+
+func (c *targetedEffectConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *targetedEffectConsumable) Describe() []string {
+	return []string{fmt.Sprintf("Leaves the target %s for %d turns.", strings.ToLower(effectDefinitions[c.Kind].Name), c.Turns)}
+}
+
+func (c *targetedEffectConsumable) GetAction(consumer *actor) action {
+	c.Engine().MessageLog.AddMessage("Select a target location.", ColorNeedsTarget, true)
+	return newSingleRangedAttackHandler(c.Engine(), false, func(x, y int) action {
+		return newItemAction(consumer, c.Parent.(*item), &[2]int{x, y})
+	})
+}
+
+func (c *targetedEffectConsumable) Activate(action *itemAction) error {
+	consumer := action.Entity
+	target := action.TargetActor()
+
+	if consumer == c.Engine().Player && !c.Engine().GameMap.Visible[action.TargetXY[0]][action.TargetXY[1]] {
+		return impossible{"You cannot target an area that you cannot see."}
+	} else if target == nil {
+		return impossible{"You must select an enemy to target."}
+	} else if target == consumer {
+		return impossible{"You cannot target yourself!"}
+	}
+
+	target.StatusEffects.Apply(c.Kind, c.Turns, c.Magnitude)
+
+	c.Consume()
+	return nil
+}
+
+type diggingConsumable struct {
+	baseConsumable
+	Range int
+}
+
+func newDiggingConsumable(r int) *diggingConsumable {
+	return &diggingConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+		Range: r,
+	}
+}
+
+func (c *diggingConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *diggingConsumable) Describe() []string {
+	return []string{fmt.Sprintf("Digs a tunnel up to %d tiles long.", c.Range)}
+}
+
+func (c *diggingConsumable) GetAction(consumer *actor) action {
+	c.Engine().MessageLog.AddMessage("Select a direction to dig.", ColorNeedsTarget, true)
+	return newSingleRangedAttackHandler(c.Engine(), false, func(x, y int) action {
+		return newItemAction(consumer, c.Parent.(*item), &[2]int{x, y})
+	})
+}
+
+// Activate bores a tunnel up to Range tiles long from the consumer towards
+// the target location. The outer wall of the level can't be dug through.
+func (c *diggingConsumable) Activate(action *itemAction) error {
+	consumer := action.Entity
+	gm := c.Engine().GameMap
+
+	dx, dy := action.TargetXY[0]-consumer.X, action.TargetXY[1]-consumer.Y
+	steps := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+	if steps == 0 {
+		return impossible{"You must select a direction to dig."}
+	}
+	endX, endY := consumer.X+dx*c.Range/steps, consumer.Y+dy*c.Range/steps
+
+	line := [][2]int{}
+	for tup := range bresenham(consumer.X, consumer.Y, endX, endY) {
+		line = append(line, tup)
+	}
+	dug := 0
+	for _, tup := range line[1:] {
+		x, y := tup[0], tup[1]
+		if x <= 0 || y <= 0 || x >= gm.Width-1 || y >= gm.Height-1 {
+			break
+		}
+		if !gm.Walkable(x, y) {
+			gm.Tiles[x][y] = newFloor()
+			dug += 1
+		}
+	}
+
+	if consumer == c.Engine().Player {
+		if dug > 0 {
+			c.Engine().MessageLog.AddMessage("You dig a tunnel through the rock!", ColorWhite, true)
+		} else {
+			c.Engine().MessageLog.AddMessage("The digging bolt finds nothing to dig through.", ColorWhite, true)
+		}
+	}
+
+	c.Consume()
+	return nil
+}
+
 type confusionConsumable struct {
 	baseConsumable
 	NumberOfTurns int
@@ -129,21 +442,27 @@ func newConfusionConsumable(numberOfTurns int) *confusionConsumable {
 	}
 }
 
+func (c *confusionConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *confusionConsumable) Describe() []string {
+	return []string{fmt.Sprintf("Confuses the target for %d turns.", c.NumberOfTurns)}
+}
+
 func (c *confusionConsumable) GetAction(consumer *actor) action {
 	c.Engine().MessageLog.AddMessage("Select a target location.", ColorNeedsTarget, true)
-	return &singleRangedAttackHandler{
-		selectIndexHandler: newSelectIndexHandler(c.Engine()),
-		Callback: func(x, y int) action {
-			return newItemAction(consumer, c.Parent.(*item), &[2]int{x, y})
-		},
-	}
+	return newSingleRangedAttackHandler(c.Engine(), false, func(x, y int) action {
+		return newItemAction(consumer, c.Parent.(*item), &[2]int{x, y})
+	})
 }
 
 func (c *confusionConsumable) Activate(action *itemAction) error {
 	consumer := action.Entity
 	target := action.TargetActor()
 
-	if !c.Engine().GameMap.Visible[action.TargetXY[0]][action.TargetXY[1]] {
+	if consumer == c.Engine().Player && !c.Engine().GameMap.Visible[action.TargetXY[0]][action.TargetXY[1]] {
 		return impossible{"You cannot target an area that you cannot see."}
 	} else if target == nil {
 		return impossible{"You must select an enemy to target."}
@@ -151,8 +470,266 @@ func (c *confusionConsumable) Activate(action *itemAction) error {
 		return impossible{"You cannot confuse yourself!"}
 	}
 
-	c.Engine().MessageLog.AddMessage(fmt.Sprintf("The eyes of the %s look vacant, as it starts to stumble around!", target.Name), ColorStatusEffectApplied, true)
-	target.AI = newConfusedEnemy(target, target.AI, c.NumberOfTurns)
+	target.StatusEffects.Apply(effectConfusion, c.NumberOfTurns, 0)
+
+	c.Consume()
+	return nil
+}
+
+type charmConsumable struct {
+	baseConsumable
+}
+
+func newCharmConsumable() *charmConsumable {
+	return &charmConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+	}
+}
+
+func (c *charmConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *charmConsumable) Describe() []string {
+	return []string{"Turns an enemy into a follower."}
+}
+
+func (c *charmConsumable) GetAction(consumer *actor) action {
+	c.Engine().MessageLog.AddMessage("Select a target location.", ColorNeedsTarget, true)
+	return newSingleRangedAttackHandler(c.Engine(), false, func(x, y int) action {
+		return newItemAction(consumer, c.Parent.(*item), &[2]int{x, y})
+	})
+}
+
+func (c *charmConsumable) Activate(action *itemAction) error {
+	consumer := action.Entity
+	target := action.TargetActor()
+
+	if !c.Engine().GameMap.Visible[action.TargetXY[0]][action.TargetXY[1]] {
+		return impossible{"You cannot target an area that you cannot see."}
+	} else if target == nil {
+		return impossible{"You must select an enemy to target."}
+	} else if consumer.IsAlly(target) {
+		return impossible{fmt.Sprintf("The %s is already on your side.", target.Name)}
+	} else if consumer.Faction.RelationTo(target.Faction) != relationHostile {
+		// Checked on the factions so that a confused reader, who takes
+		// everyone for a foe, still can't charm a shopkeeper away.
+		return impossible{"You must select an enemy to target."}
+	}
+
+	c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s is charmed and starts following you!", target.Name), ColorStatusEffectApplied, true)
+	target.Faction = consumer.Faction
+	target.AI = newAlliedCompanion(target, 3)
+
+	c.Consume()
+	return nil
+}
+
+type identifyConsumable struct {
+	baseConsumable
+}
+
+func newIdentifyConsumable() *identifyConsumable {
+	return &identifyConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+	}
+}
+
+func (c *identifyConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *identifyConsumable) Describe() []string {
+	return []string{"Reveals what an item is."}
+}
+
+func (c *identifyConsumable) GetAction(consumer *actor) action {
+	return newInventorySelectHandler(c.Engine(), "Select an item to identify", func(it *item) action {
+		act := newItemAction(consumer, c.Parent.(*item), nil)
+		act.TargetItem = it
+		return act
+	})
+}
+
+func (c *identifyConsumable) Activate(action *itemAction) error {
+	target := action.TargetItem
+	if target == nil {
+		return impossible{"You must select an item to identify."}
+	}
+	name := target.DisplayName()
+	if !c.Engine().Knowledge.Identify(target) {
+		return impossible{fmt.Sprintf("You already know what the %s is.", name)}
+	}
+	c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s is revealed as the %s.", name, target.FullName()), ColorStatusEffectApplied, true)
+
+	c.Consume()
+	return nil
+}
+
+type teleportConsumable struct {
+	baseConsumable
+}
+
+func newTeleportConsumable() *teleportConsumable {
+	return &teleportConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+	}
+}
+
+func (c *teleportConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *teleportConsumable) Describe() []string {
+	return []string{"Teleports you to a random spot on the level."}
+}
+
+func (c *teleportConsumable) Activate(act *itemAction) error {
+	consumer := act.Entity
+	gm := c.Engine().GameMap
+
+	candidates := [][2]int{}
+	for w := range gm.Tiles {
+		for h := range gm.Tiles[w] {
+			if gm.Walkable(w, h) && gm.GetBlockingEntityAtLocation(w, h) == nil {
+				candidates = append(candidates, [2]int{w, h})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return impossible{"Nothing happens."}
+	}
+
+	if consumer == c.Engine().Player {
+		c.Engine().MessageLog.AddMessage("You feel yourself yanked away!", ColorStatusEffectApplied, true)
+	} else if gm.Visible[consumer.X][consumer.Y] {
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s vanishes!", consumer.Name), ColorWhite, true)
+	}
+	consumer.SetPostion(candidates[rand.Intn(len(candidates))])
+
+	c.Consume()
+	return nil
+}
+
+type magicMappingConsumable struct {
+	baseConsumable
+}
+
+func newMagicMappingConsumable() *magicMappingConsumable {
+	return &magicMappingConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+	}
+}
+
+func (c *magicMappingConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *magicMappingConsumable) Describe() []string {
+	return []string{"Reveals the layout of the level."}
+}
+
+// Activate reveals every floor tile on the level along with the walls that
+// border them, leaving the solid rock in between dark.
+func (c *magicMappingConsumable) Activate(act *itemAction) error {
+	gm := c.Engine().GameMap
+	for w := range gm.Tiles {
+		for h := range gm.Tiles[w] {
+			if !gm.Walkable(w, h) {
+				continue
+			}
+			for dx := -1; dx <= 1; dx += 1 {
+				for dy := -1; dy <= 1; dy += 1 {
+					if gm.InBounds(w+dx, h+dy) {
+						gm.Explored[w+dx][h+dy] = true
+					}
+				}
+			}
+		}
+	}
+	if act.Entity == c.Engine().Player {
+		c.Engine().MessageLog.AddMessage("A map of the level forms in your mind!", ColorStatusEffectApplied, true)
+	}
+
+	c.Consume()
+	return nil
+}
+
+type rechargeConsumable struct {
+	baseConsumable
+}
+
+func newRechargeConsumable() *rechargeConsumable {
+	return &rechargeConsumable{
+		baseConsumable: baseConsumable{
+			baseComponent: baseComponent{
+				Parent: nil,
+			},
+		},
+	}
+}
+
+func (c *rechargeConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *rechargeConsumable) Describe() []string {
+	return []string{"Refills a wand. Each recharge makes the next one more likely to blow it up."}
+}
+
+func (c *rechargeConsumable) GetAction(consumer *actor) action {
+	return newInventorySelectHandler(c.Engine(), "Select a wand to recharge", func(it *item) action {
+		act := newItemAction(consumer, c.Parent.(*item), nil)
+		act.TargetItem = it
+		return act
+	})
+}
+
+// Activate refills the target wand. Every earlier recharge adds a one in three
+// chance that the wand explodes instead.
+func (c *rechargeConsumable) Activate(action *itemAction) error {
+	consumer := action.Entity
+	target := action.TargetItem
+	if target == nil {
+		return impossible{"You must select a wand to recharge."}
+	}
+	if target.MaxCharges == 0 {
+		return impossible{fmt.Sprintf("The %s cannot be recharged.", target.DisplayName())}
+	}
+
+	// The first recharge is always safe, after that the wand blows up one
+	// time in three, then two in three, then every time.
+	if rand.Intn(3) < target.Recharged {
+		damage := target.MaxCharges * 2
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s explodes in your hands, for %d damage!", target.DisplayName(), consumer.Fighter.Resist(damage, damageFire)), ColorPlayerDie, true)
+		consumer.Inventory.Remove(target)
+		consumer.Fighter.TakeDamage(damage, damageFire, nil)
+	} else {
+		target.Charges = target.MaxCharges
+		target.Recharged += 1
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s glows and is fully recharged.", target.DisplayName()), ColorStatusEffectApplied, true)
+	}
 
 	c.Consume()
 	return nil
@@ -176,29 +753,40 @@ func newFireballDamageConsumable(damage, radius int) *fireballDamageConsumable {
 	}
 }
 
+func (c *fireballDamageConsumable) Copy() consumable {
+	clone := *c
+	return &clone
+}
+
+func (c *fireballDamageConsumable) Describe() []string {
+	return []string{
+		fmt.Sprintf("Engulfs an area in flames for %d fire damage.", c.Damage),
+		fmt.Sprintf("Radius: %d", c.Radius),
+	}
+}
+
 func (c *fireballDamageConsumable) GetAction(consumer *actor) action {
 	c.Engine().MessageLog.AddMessage("Select a target location.", ColorNeedsTarget, true)
-	return &areaRangedAttackHandler{
-		selectIndexHandler: newSelectIndexHandler(c.Engine()),
-		Radius:             c.Radius,
-		Callback: func(x, y int) action {
-			return newItemAction(consumer, c.Parent.(*item), &[2]int{x, y})
-		},
-	}
+	return newAreaRangedAttackHandler(c.Engine(), c.Radius, func(x, y int) action {
+		return newItemAction(consumer, c.Parent.(*item), &[2]int{x, y})
+	})
 }
 
 func (c *fireballDamageConsumable) Activate(action *itemAction) error {
 	targetXY := action.TargetXY
 
-	if !c.Engine().GameMap.Visible[targetXY[0]][targetXY[1]] {
+	if action.Entity == c.Engine().Player && !c.Engine().GameMap.Visible[targetXY[0]][targetXY[1]] {
 		return impossible{"You cannot target an area that you cannot see."}
 	}
 
+	c.Engine().MakeNoise(targetXY[0], targetXY[1], noiseExplosion)
+
+	area := c.Engine().GameMap.BlastArea(targetXY[0], targetXY[1], c.Radius)
 	targetHit := false
 	for _, a := range c.Engine().GameMap.Actors() {
-		if a.Distance(targetXY[0], targetXY[1]) <= float64(c.Radius+1) {
-			c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s is engulfed in a fiery explosion, taking %d damage!", a.Name, c.Damage), ColorWhite, true)
-			a.Fighter.TakeDamage(c.Damage)
+		if area[[2]int{a.X, a.Y}] {
+			c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s is engulfed in a fiery explosion, taking %d damage!", a.Name, a.Fighter.Resist(c.Damage, damageFire)), ColorWhite, true)
+			a.Fighter.TakeDamage(c.Damage, damageFire, action.Entity)
 			targetHit = true
 		}
 	}
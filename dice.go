@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+)
+
+// dice describes a roll such as 1d6+2: Count dice with Sides faces plus Bonus.
+type dice struct {
+	Count int
+	Sides int
+	Bonus int
+}
+
+var diceNotation = regexp.MustCompile(`^(\d+)d(\d+)([+-]\d+)?$`)
+
+func parseDice(notation string) (dice, error) {
+	m := diceNotation.FindStringSubmatch(notation)
+	if m == nil {
+		return dice{}, fmt.Errorf("invalid dice notation: %q", notation)
+	}
+	count, _ := strconv.Atoi(m[1])
+	sides, _ := strconv.Atoi(m[2])
+	bonus := 0
+	if m[3] != "" {
+		bonus, _ = strconv.Atoi(m[3])
+	}
+	return dice{Count: count, Sides: sides, Bonus: bonus}, nil
+}
+
+func mustParseDice(notation string) dice {
+	d, err := parseDice(notation)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d dice) IsZero() bool {
+	return d.Count == 0 || d.Sides == 0
+}
+
+func (d dice) Roll(r *rand.Rand) int {
+	total := d.Bonus
+	for i := 0; i < d.Count; i++ {
+		total += r.Intn(d.Sides) + 1
+	}
+	return total
+}
+
+func (d dice) String() string {
+	if d.Bonus > 0 {
+		return fmt.Sprintf("%dd%d+%d", d.Count, d.Sides, d.Bonus)
+	} else if d.Bonus < 0 {
+		return fmt.Sprintf("%dd%d%d", d.Count, d.Sides, d.Bonus)
+	}
+	return fmt.Sprintf("%dd%d", d.Count, d.Sides)
+}
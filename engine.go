@@ -2,6 +2,7 @@ package main
 
 import (
 	"math"
+	"math/rand"
 
 	ebiten "github.com/hajimehoshi/ebiten/v2"
 	"golang.org/x/image/font"
@@ -13,39 +14,114 @@ type engine struct {
 	MouseLocation [2]int
 	Player        *actor
 	Font          font.Face
+	Seed          int64
+	Rand          *rand.Rand // source for combat rolls, reproducible from Seed
+	Turn          int
+	Knowledge     *itemKnowledge
+	Autopickup    []string // names of the autopickup rules in use
+	LastTarget    *actor   // the actor the player last aimed at
 }
 
-func NewEngine(pl *actor, font font.Face) *engine {
+func NewEngine(pl *actor, font font.Face, seed int64) *engine {
 	e := &engine{
 		Player: pl,
 		Font:   font,
+		Seed:   seed,
+		Rand:   rand.New(rand.NewSource(seed)),
 	}
+	e.Knowledge = newItemKnowledge(seed)
 	e.MessageLog = NewMessageLog()
 	e.MouseLocation = [2]int{0, 0}
 	return e
 }
 
 func (e *engine) HandleEnemyTurns() error {
-	for _, entity := range e.GameMap.Entities {
-		if entity == e.Player {
-			continue
+	// A hasted player gets a free move every other turn, a slowed or
+	// burdened one gives everybody else an extra move.
+	rounds := 1
+	if e.Player.StatusEffects.Has(effectHaste) && e.Turn%2 == 0 {
+		rounds = 0
+	} else if e.Player.StatusEffects.Has(effectSlow) || e.Player.Inventory.IsBurdened() {
+		rounds = 2
+	}
+	for i := 0; i < rounds; i++ {
+		// Actors pick items up and drop them during their turns, which
+		// reshuffles GameMap.Entities, so go over a snapshot.
+		for _, a := range e.GameMap.Actors() {
+			if a == e.Player {
+				continue
+			}
+			if err := e.handleActorTurn(a); err != nil {
+				return err
+			}
 		}
-		if a, ok := entity.(*actor); ok {
-			if a.IsAlive() {
-				if err := a.AI.Perform(); err != nil {
-					switch err.(type) {
-					case impossible:
-						// pass
-					default:
-						return err
-					}
-				}
+	}
+	return nil
+}
+
+func (e *engine) handleActorTurn(a *actor) error {
+	moves := 1
+	if a.StatusEffects.Has(effectParalysis) {
+		moves = 0
+	} else if a.StatusEffects.Has(effectHaste) {
+		moves = 2
+	} else if a.StatusEffects.Has(effectSlow) && e.Turn%2 == 1 {
+		moves = 0
+	}
+	for i := 0; i < moves && a.IsAlive(); i++ {
+		var err error
+		if a.StatusEffects.Has(effectConfusion) {
+			err = stumble(a)
+		} else {
+			err = a.AI.Perform()
+		}
+		if err != nil {
+			switch err.(type) {
+			case impossible:
+				// pass
+			default:
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+// EndTurn advances the turn counter and lets status effects run their course.
+func (e *engine) EndTurn() {
+	e.Turn += 1
+	for _, a := range e.GameMap.Actors() {
+		a.StatusEffects.Tick()
+	}
+	if e.Player.Hunger != nil && e.Player.IsAlive() {
+		e.Player.Hunger.Tick()
+	}
+	e.Player.Inventory.UpdateBurden()
+}
+
+const (
+	noiseCombat    = 6
+	noiseExplosion = 12
+)
+
+// MakeNoise wakes up dormant actors within earshot of (x, y). Sleepers only
+// hear half as far.
+func (e *engine) MakeNoise(x, y, loudness int) {
+	for _, a := range e.GameMap.Actors() {
+		d, ok := a.AI.(*dormantEnemy)
+		if !ok {
+			continue
+		}
+		hearing := float64(loudness)
+		if d.Asleep {
+			hearing /= 2
+		}
+		if a.Distance(x, y) <= hearing {
+			d.Wake()
+		}
+	}
+}
+
 func (e engine) Render(screen *ebiten.Image) {
 	e.GameMap.Render(screen, e.Font)
 
@@ -53,6 +129,14 @@ func (e engine) Render(screen *ebiten.Image) {
 
 	RenderBar(screen, e.Font, e.Player.Fighter.HP, e.Player.Fighter.MaxHP, 200)
 
+	RenderLevel(screen, e.Font, 0, 46, e.Player)
+
+	RenderHunger(screen, e.Font, 0, 47, e.Player)
+
+	RenderGold(screen, e.Font, 10, 47, e.Player)
+
+	RenderStatusEffects(screen, e.Font, 61, 45, 19, 5, e.Player)
+
 	RenderNamesAtMouseLocation(screen, e.Font, 21, 44, &e)
 }
 
@@ -62,7 +146,11 @@ func (e *engine) UpdateFov() {
 			e.GameMap.Visible[w][h] = false
 		}
 	}
-	e.computeFov(e.Player.X, e.Player.Y, 8)
+	radius := sightRadius
+	if e.Player.StatusEffects.Has(effectBlindness) {
+		radius = 2
+	}
+	e.computeFov(e.Player.X, e.Player.Y, radius)
 	for w, v := range e.GameMap.Visible {
 		for h := range v {
 			if e.GameMap.Visible[w][h] {
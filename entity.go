@@ -53,14 +53,65 @@ func (e *baseEntity) Distance(x, y int) float64 {
 	return math.Sqrt(math.Pow(float64(x-e.X), 2) + math.Pow(float64(y-e.Y), 2))
 }
 
+type faction int
+
+const (
+	factionPlayer faction = iota
+	factionMonsters
+	factionOrcs
+	factionGoblins
+	factionKobolds
+	factionShopkeepers
+)
+
+type relation int
+
+const (
+	relationHostile relation = iota
+	relationNeutral
+	relationAllied
+)
+
+// factionRelations lists how two different factions get along. The table is
+// symmetric, pairs missing from it are neutral to each other.
+var factionRelations = map[[2]faction]relation{
+	{factionPlayer, factionMonsters}: relationHostile,
+	{factionPlayer, factionOrcs}:     relationHostile,
+	{factionPlayer, factionGoblins}:  relationHostile,
+	{factionPlayer, factionKobolds}:  relationHostile,
+	{factionOrcs, factionGoblins}:    relationAllied,
+	{factionGoblins, factionKobolds}: relationHostile,
+}
+
+func (f faction) RelationTo(other faction) relation {
+	if f == other {
+		return relationAllied
+	}
+	if r, ok := factionRelations[[2]faction{f, other}]; ok {
+		return r
+	}
+	if r, ok := factionRelations[[2]faction{other, f}]; ok {
+		return r
+	}
+	return relationNeutral
+}
+
+const sightRadius = 8
+
 type actor struct {
 	*baseEntity
-	AI        AI
-	Fighter   *fighter
-	Inventory *inventory
+	AI            AI
+	Fighter       *fighter
+	Inventory     *inventory
+	Equipment     *equipment
+	Level         *level
+	Hunger        *hunger // only the player gets hungry
+	StatusEffects *statusEffects
+	Faction       faction
+	Gold          int
 }
 
-func newActor(x, y int, char string, color color.RGBA, name string, fig *fighter, inv *inventory) *actor {
+func newActor(x, y int, char string, color color.RGBA, name string, fig *fighter, inv *inventory, eq *equipment, lvl *level) *actor {
 	a := &actor{
 		baseEntity: &baseEntity{
 			X:              x,
@@ -71,11 +122,18 @@ func newActor(x, y int, char string, color color.RGBA, name string, fig *fighter
 			BlocksMovement: true,
 			RO:             RenderOrder_Actor,
 		},
-		Fighter:   fig,
-		Inventory: inv,
+		Fighter:       fig,
+		Inventory:     inv,
+		Equipment:     eq,
+		Level:         lvl,
+		StatusEffects: newStatusEffects(),
+		Faction:       factionMonsters,
 	}
 	a.Fighter.Parent = a
 	a.Inventory.Parent = a
+	a.Equipment.Parent = a
+	a.Level.Parent = a
+	a.StatusEffects.Parent = a
 	a.AI = NewHostileEnemy(a)
 	return a
 }
@@ -87,6 +145,57 @@ func (e actor) IsAlive() bool {
 	return false
 }
 
+func (e *actor) RelationTo(other *actor) relation {
+	if e == other {
+		return relationAllied
+	}
+	// A confused creature can't tell friend from foe and lashes out at anyone.
+	if e.StatusEffects.Has(effectConfusion) {
+		return relationHostile
+	}
+	return e.Faction.RelationTo(other.Faction)
+}
+
+func (e *actor) IsAlly(other *actor) bool {
+	return e.RelationTo(other) == relationAllied
+}
+
+func (e *actor) IsHostile(other *actor) bool {
+	return e.RelationTo(other) == relationHostile
+}
+
+// CanSee reports whether other is within the actor's sight. The player's field
+// of view is already computed, so anything involving the player uses it.
+func (e *actor) CanSee(other *actor) bool {
+	gm := e.GameMap()
+	player := gm.Engine.Player
+	if e == player {
+		return gm.Visible[other.X][other.Y]
+	}
+	if e.StatusEffects.Has(effectBlindness) {
+		return e.Distance(other.X, other.Y) < 2
+	}
+	if other == player {
+		return gm.Visible[e.X][e.Y]
+	}
+	if e.X == other.X && e.Y == other.Y {
+		return true
+	}
+	if e.Distance(other.X, other.Y) > sightRadius {
+		return false
+	}
+	line := [][2]int{}
+	for tup := range bresenham(e.X, e.Y, other.X, other.Y) {
+		line = append(line, tup)
+	}
+	for _, tup := range line[1 : len(line)-1] {
+		if !gm.Tiles[tup[0]][tup[1]].Transparent {
+			return false
+		}
+	}
+	return true
+}
+
 func (e *actor) Spawn(gm *gameMap, x, y int) entity {
 	clone := e
 	clone.X = x
@@ -123,9 +232,23 @@ func (e *actor) Move(dx, dy int) {
 type item struct {
 	*baseEntity
 	Consumable consumable
+	Equippable *equippable
+	Category   itemCategory
+	Fragile    bool // shatters instead of landing when thrown
+	Quantity   int
+	Charges    int
+	MaxCharges int // zero for anything that isn't a wand
+	Recharged  int // how many times it has been recharged
+	Rarity     rarity
+	Prefix     string
+	Suffix     string
+	Value      int   // base price in gold of a single item
+	Weight     int   // of a single item, in tenths of a pound
+	Gold       int   // set on gold piles, which never go into an inventory
+	Shop       *shop // set while the item is for sale
 }
 
-func newItem(x, y int, char string, color color.RGBA, name string, c consumable) *item {
+func newItem(x, y int, char string, color color.RGBA, name string, c consumable, eq *equippable) *item {
 	i := &item{
 		baseEntity: &baseEntity{
 			X:              x,
@@ -136,12 +259,127 @@ func newItem(x, y int, char string, color color.RGBA, name string, c consumable)
 			BlocksMovement: true,
 			RO:             RenderOrder_Actor,
 		},
+		Quantity: 1,
+	}
+	if c != nil {
+		i.Consumable = c
+		i.Consumable.SetParent(i)
+	}
+	if eq != nil {
+		i.Equippable = eq
+		i.Equippable.SetParent(i)
 	}
-	i.Consumable = c
-	i.Consumable.SetParent(i)
 	return i
 }
 
+// ThrownDamage is what the item deals when thrown by hand. Only melee weapons
+// hurt more than a bruise; ammunition needs its launcher.
+func (e *item) ThrownDamage() dice {
+	if e.Equippable != nil && e.Equippable.AmmoKind == ammoNone && !e.Equippable.Damage.IsZero() {
+		return e.Equippable.Damage
+	}
+	return dice{Count: 1, Sides: 2}
+}
+
+// DisplayName is the item's name as far as the player knows it.
+func (e *item) DisplayName() string {
+	if e.Gold > 0 {
+		return fmt.Sprintf("%d gold", e.Gold)
+	}
+	k := e.GameMap().Engine.Knowledge
+	if !k.IsKnown(e) {
+		return k.Name(e)
+	}
+	return e.FullName()
+}
+
+// DisplayColor is the color of the item's rarity, which shows only once the
+// item has been identified.
+func (e *item) DisplayColor() color.RGBA {
+	if !e.GameMap().Engine.Knowledge.IsKnown(e) {
+		return ColorWhite
+	}
+	return e.Rarity.Color()
+}
+
+// FullName is the item's true name along with its affixes.
+func (e *item) FullName() string {
+	name := e.Name
+	if e.Prefix != "" {
+		name = fmt.Sprintf("%s %s", e.Prefix, name)
+	}
+	if e.Suffix != "" {
+		name = fmt.Sprintf("%s %s", name, e.Suffix)
+	}
+	return name
+}
+
+// Describe lists what the player knows about the item.
+func (e *item) Describe() []string {
+	lines := []string{}
+	if !e.GameMap().Engine.Knowledge.IsKnown(e) {
+		lines = append(lines, "You don't know what this does.")
+	} else {
+		if e.Consumable != nil {
+			lines = append(lines, e.Consumable.Describe()...)
+		}
+		if e.Equippable != nil {
+			lines = append(lines, e.Equippable.Describe()...)
+		}
+		if e.Rarity != rarityCommon {
+			lines = append(lines, fmt.Sprintf("Rarity: %s", e.Rarity))
+		}
+	}
+	if e.MaxCharges > 0 {
+		lines = append(lines, fmt.Sprintf("Charges: %d/%d", e.Charges, e.MaxCharges))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Weight: %s", formatWeight(e.TotalWeight())),
+		fmt.Sprintf("Value: %d gold", e.Price()),
+	)
+	return lines
+}
+
+// Stackable items share one inventory entry with identical ones. Consumables
+// and ammunition stack; weapons, armor and wands are kept apart.
+func (e *item) Stackable() bool {
+	if e.MaxCharges > 0 {
+		return false
+	}
+	return e.Equippable == nil || e.Equippable.AmmoKind != ammoNone
+}
+
+// IsEmpty reports whether the item is a wand that has run out of charges.
+func (e *item) IsEmpty() bool {
+	return e.MaxCharges > 0 && e.Charges == 0
+}
+
+// CanStackWith matches on the item's true identity. Unidentified items with
+// different affixes keep separate entries even though they look the same.
+func (e *item) CanStackWith(other *item) bool {
+	return e != other && e.Stackable() && other.Stackable() && e.FullName() == other.FullName() && e.Rarity == other.Rarity && e.Char == other.Char
+}
+
+// Split takes n items off the stack and returns them as a new item.
+func (e *item) Split(n int) *item {
+	base := *e.baseEntity
+	clone := &item{}
+	*clone = *e
+	clone.baseEntity = &base
+	clone.Quantity = n
+	if e.Consumable != nil {
+		clone.Consumable = e.Consumable.Copy()
+		clone.Consumable.SetParent(clone)
+	}
+	if e.Equippable != nil {
+		eq := *e.Equippable
+		clone.Equippable = &eq
+		clone.Equippable.SetParent(clone)
+	}
+	e.Quantity -= n
+	return clone
+}
+
 func (e *item) Spawn(gm *gameMap, x, y int) entity {
 	clone := e
 	clone.X = x
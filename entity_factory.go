@@ -1,9 +1,12 @@
 package main
 
-import "image/color"
+import (
+	"image/color"
+	"math/rand"
+)
 
 func newPlayer() *actor {
-	return newActor(
+	player := newActor(
 		0,
 		0,
 		"@",
@@ -18,17 +21,57 @@ func newPlayer() *actor {
 			baseComponent: &baseComponent{
 				Parent: nil,
 			},
-			MaxHP:   30,
-			HP:      30,
-			Defense: 2,
-			Power:   5,
+			MaxHP:       30,
+			HP:          30,
+			BaseDefense: 1,
+			BasePower:   3,
+			Evasion:     2,
+			Damage:      mustParseDice("1d3+1"),
 		},
 		newInventory(26),
+		newEquipment(),
+		newLevel(200, 150, 0),
 	)
+	player.Faction = factionPlayer
+	player.Hunger = newHunger(1000)
+	player.Hunger.Parent = player
+	return player
+}
+
+func newDog() *actor {
+	dog := newActor(
+		0,
+		0,
+		"d",
+		color.RGBA{
+			R: 191,
+			G: 127,
+			B: 63,
+			A: 255,
+		},
+		"Dog",
+		&fighter{
+			baseComponent: &baseComponent{
+				Parent: nil,
+			},
+			MaxHP:       12,
+			HP:          12,
+			BaseDefense: 0,
+			BasePower:   3,
+			Evasion:     3,
+			Damage:      mustParseDice("1d4+1"),
+		},
+		newInventory(0),
+		newEquipment(),
+		newLevel(0, 0, 0),
+	)
+	dog.Faction = factionPlayer
+	dog.AI = newAlliedCompanion(dog, 2)
+	return dog
 }
 
 func newOrc() *actor {
-	return newActor(
+	orc := newActor(
 		0,
 		0,
 		"o",
@@ -43,17 +86,26 @@ func newOrc() *actor {
 			baseComponent: &baseComponent{
 				Parent: nil,
 			},
-			MaxHP:   10,
-			HP:      10,
-			Defense: 0,
-			Power:   3,
+			MaxHP:       10,
+			HP:          10,
+			BaseDefense: 0,
+			BasePower:   3,
+			Evasion:     0,
+			Damage:      mustParseDice("1d4+1"),
 		},
-		newInventory(0),
+		newInventory(2),
+		newEquipment(),
+		newLevel(0, 0, 35),
 	)
+	ai := NewHostileEnemy(orc)
+	ai.FleeThreshold = 0.4
+	orc.AI = newDormantEnemy(orc, ai, 30)
+	orc.Faction = factionOrcs
+	return orc
 }
 
 func newTroll() *actor {
-	return newActor(
+	troll := newActor(
 		0,
 		0,
 		"T",
@@ -68,17 +120,184 @@ func newTroll() *actor {
 			baseComponent: &baseComponent{
 				Parent: nil,
 			},
-			MaxHP:   16,
-			HP:      16,
-			Defense: 1,
-			Power:   4,
+			MaxHP:       16,
+			HP:          16,
+			BaseDefense: 1,
+			BasePower:   4,
+			Evasion:     0,
+			Damage:      mustParseDice("1d6+1"),
+			Resistances: map[damageType]bool{
+				damagePoison: true,
+			},
+			Vulnerabilities: map[damageType]bool{
+				damageFire: true,
+			},
+		},
+		newInventory(0),
+		newEquipment(),
+		newLevel(0, 0, 100),
+	)
+	troll.AI = newDormantEnemy(troll, troll.AI, 10)
+	troll.Faction = factionOrcs
+	return troll
+}
+
+func newKoboldArcher() *actor {
+	archer := newActor(
+		0,
+		0,
+		"k",
+		color.RGBA{
+			R: 191,
+			G: 127,
+			B: 63,
+			A: 255,
+		},
+		"Kobold Archer",
+		&fighter{
+			baseComponent: &baseComponent{
+				Parent: nil,
+			},
+			MaxHP:       8,
+			HP:          8,
+			BaseDefense: 0,
+			BasePower:   3,
+			Evasion:     2,
+			Damage:      mustParseDice("1d4+1"),
+		},
+		newInventory(0),
+		newEquipment(),
+		newLevel(0, 0, 40),
+	)
+	archer.AI = newDormantEnemy(archer, newRangedEnemy(archer, 8, "an arrow", damagePhysical), 50)
+	archer.Faction = factionKobolds
+	return archer
+}
+
+func newGoblinShaman() *actor {
+	shaman := newActor(
+		0,
+		0,
+		"g",
+		color.RGBA{
+			R: 127,
+			G: 63,
+			B: 191,
+			A: 255,
+		},
+		"Goblin Shaman",
+		&fighter{
+			baseComponent: &baseComponent{
+				Parent: nil,
+			},
+			MaxHP:       6,
+			HP:          6,
+			BaseDefense: 0,
+			BasePower:   4,
+			Evasion:     1,
+			Damage:      mustParseDice("1d6"),
+		},
+		newInventory(3),
+		newEquipment(),
+		newLevel(0, 0, 50),
+	)
+	shaman.AI = newDormantEnemy(shaman, newRangedEnemy(shaman, 6, "a fire bolt", damageFire), 40)
+	shaman.Faction = factionGoblins
+	return shaman
+}
+
+func newFireElemental() *actor {
+	elemental := newActor(
+		0,
+		0,
+		"E",
+		color.RGBA{
+			R: 255,
+			G: 127,
+			B: 0,
+			A: 255,
+		},
+		"Fire Elemental",
+		&fighter{
+			baseComponent: &baseComponent{
+				Parent: nil,
+			},
+			MaxHP:       14,
+			HP:          14,
+			BaseDefense: 1,
+			BasePower:   3,
+			Evasion:     1,
+			Damage:      mustParseDice("1d6+1"),
+			DamageType:  damageFire,
+			Resistances: map[damageType]bool{
+				damageFire:   true,
+				damagePoison: true,
+			},
+			Vulnerabilities: map[damageType]bool{
+				damageCold: true,
+			},
+		},
+		newInventory(0),
+		newEquipment(),
+		newLevel(0, 0, 80),
+	)
+	elemental.AI = newDormantEnemy(elemental, elemental.AI, 20)
+	return elemental
+}
+
+func newShopkeeperActor() *actor {
+	keeper := newActor(
+		0,
+		0,
+		"@",
+		color.RGBA{
+			R: 255,
+			G: 223,
+			B: 63,
+			A: 255,
+		},
+		"Shopkeeper",
+		&fighter{
+			baseComponent: &baseComponent{
+				Parent: nil,
+			},
+			MaxHP:       60,
+			HP:          60,
+			BaseDefense: 4,
+			BasePower:   8,
+			Evasion:     3,
+			Damage:      mustParseDice("2d6+2"),
 		},
 		newInventory(0),
+		newEquipment(),
+		newLevel(0, 0, 200),
+	)
+	keeper.AI = newShopkeeper(keeper)
+	keeper.Faction = factionShopkeepers
+	return keeper
+}
+
+func newGoldPile(amount int) *item {
+	it := newItem(
+		0,
+		0,
+		"$",
+		color.RGBA{
+			R: 255,
+			G: 215,
+			B: 0,
+			A: 255,
+		},
+		"Gold",
+		nil,
+		nil,
 	)
+	it.Gold = amount
+	return it
 }
 
 func newHealthPortion() *item {
-	return newItem(
+	it := newItem(
 		0,
 		0,
 		"!",
@@ -90,11 +309,145 @@ func newHealthPortion() *item {
 		},
 		"Health Portion",
 		newHealingConsumable(4),
+		nil,
 	)
+	it.Category = categoryPotion
+	it.Fragile = true
+	it.Value = 20
+	it.Weight = 5
+	return it
+}
+
+func newRegenerationPotion() *item {
+	it := newItem(
+		0,
+		0,
+		"!",
+		color.RGBA{
+			R: 0,
+			G: 255,
+			B: 127,
+			A: 255,
+		},
+		"Regeneration Potion",
+		newStatusEffectConsumable(effectRegeneration, 10, 1),
+		nil,
+	)
+	it.Category = categoryPotion
+	it.Fragile = true
+	it.Value = 40
+	it.Weight = 5
+	return it
+}
+
+func newHastePotion() *item {
+	it := newItem(
+		0,
+		0,
+		"!",
+		color.RGBA{
+			R: 63,
+			G: 191,
+			B: 255,
+			A: 255,
+		},
+		"Haste Potion",
+		newStatusEffectConsumable(effectHaste, 15, 0),
+		nil,
+	)
+	it.Category = categoryPotion
+	it.Fragile = true
+	it.Value = 40
+	it.Weight = 5
+	return it
+}
+
+func newPoisonPotion() *item {
+	it := newItem(
+		0,
+		0,
+		"!",
+		color.RGBA{
+			R: 127,
+			G: 255,
+			B: 0,
+			A: 255,
+		},
+		"Poison Potion",
+		newStatusEffectConsumable(effectPoison, 6, 1),
+		nil,
+	)
+	it.Category = categoryPotion
+	it.Fragile = true
+	it.Value = 10
+	it.Weight = 5
+	return it
+}
+
+func newBlindnessPotion() *item {
+	it := newItem(
+		0,
+		0,
+		"!",
+		color.RGBA{
+			R: 127,
+			G: 127,
+			B: 127,
+			A: 255,
+		},
+		"Blindness Potion",
+		newStatusEffectConsumable(effectBlindness, 12, 0),
+		nil,
+	)
+	it.Category = categoryPotion
+	it.Fragile = true
+	it.Value = 10
+	it.Weight = 5
+	return it
+}
+
+func newRation() *item {
+	it := newItem(
+		0,
+		0,
+		"%",
+		color.RGBA{
+			R: 191,
+			G: 127,
+			B: 63,
+			A: 255,
+		},
+		"Ration",
+		newFoodConsumable(600),
+		nil,
+	)
+	it.Value = 15
+	it.Weight = 10
+	return it
+}
+
+func newApple() *item {
+	it := newItem(
+		0,
+		0,
+		"%",
+		color.RGBA{
+			R: 255,
+			G: 63,
+			B: 63,
+			A: 255,
+		},
+		"Apple",
+		newFoodConsumable(150),
+		nil,
+	)
+	it.Value = 5
+	it.Weight = 3
+	return it
 }
 
 func newLightningScroll() *item {
-	return newItem(
+	it := newItem(
 		0,
 		0,
 		"~",
@@ -106,11 +459,16 @@ func newLightningScroll() *item {
 		},
 		"Lightning Scroll",
 		newLightningDamageConsumable(20, 5),
+		nil,
 	)
+	it.Category = categoryScroll
+	it.Value = 50
+	it.Weight = 1
+	return it
 }
 
 func newConfusionScroll() *item {
-	return newItem(
+	it := newItem(
 		0,
 		0,
 		"~",
@@ -122,11 +480,16 @@ func newConfusionScroll() *item {
 		},
 		"Confusion Scroll",
 		newConfusionConsumable(10),
+		nil,
 	)
+	it.Category = categoryScroll
+	it.Value = 40
+	it.Weight = 1
+	return it
 }
 
 func newFireballScroll() *item {
-	return newItem(
+	it := newItem(
 		0,
 		0,
 		"~",
@@ -138,5 +501,474 @@ func newFireballScroll() *item {
 		},
 		"Fireball Scroll",
 		newFireballDamageConsumable(12, 3),
+		nil,
+	)
+	it.Category = categoryScroll
+	it.Value = 60
+	it.Weight = 1
+	return it
+}
+
+func newCharmScroll() *item {
+	it := newItem(
+		0,
+		0,
+		"~",
+		color.RGBA{
+			R: 255,
+			G: 127,
+			B: 191,
+			A: 255,
+		},
+		"Charm Scroll",
+		newCharmConsumable(),
+		nil,
+	)
+	it.Category = categoryScroll
+	it.Value = 60
+	it.Weight = 1
+	return it
+}
+
+func newIdentifyScroll() *item {
+	it := newItem(
+		0,
+		0,
+		"~",
+		color.RGBA{
+			R: 255,
+			G: 255,
+			B: 255,
+			A: 255,
+		},
+		"Identify Scroll",
+		newIdentifyConsumable(),
+		nil,
+	)
+	it.Category = categoryScroll
+	it.Value = 30
+	it.Weight = 1
+	return it
+}
+
+func newTeleportScroll() *item {
+	it := newItem(
+		0,
+		0,
+		"~",
+		color.RGBA{
+			R: 127,
+			G: 127,
+			B: 255,
+			A: 255,
+		},
+		"Teleport Scroll",
+		newTeleportConsumable(),
+		nil,
+	)
+	it.Category = categoryScroll
+	it.Value = 40
+	it.Weight = 1
+	return it
+}
+
+func newMagicMappingScroll() *item {
+	it := newItem(
+		0,
+		0,
+		"~",
+		color.RGBA{
+			R: 191,
+			G: 191,
+			B: 127,
+			A: 255,
+		},
+		"Magic Mapping Scroll",
+		newMagicMappingConsumable(),
+		nil,
+	)
+	it.Category = categoryScroll
+	it.Value = 50
+	it.Weight = 1
+	return it
+}
+
+func newDetectMonstersScroll() *item {
+	it := newItem(
+		0,
+		0,
+		"~",
+		color.RGBA{
+			R: 255,
+			G: 127,
+			B: 127,
+			A: 255,
+		},
+		"Detect Monsters Scroll",
+		newStatusEffectConsumable(effectDetectMonsters, 30, 0),
+		nil,
+	)
+	it.Category = categoryScroll
+	it.Value = 30
+	it.Weight = 1
+	return it
+}
+
+func newRechargeScroll() *item {
+	it := newItem(
+		0,
+		0,
+		"~",
+		color.RGBA{
+			R: 127,
+			G: 255,
+			B: 255,
+			A: 255,
+		},
+		"Recharge Scroll",
+		newRechargeConsumable(),
+		nil,
+	)
+	it.Category = categoryScroll
+	it.Value = 80
+	it.Weight = 1
+	return it
+}
+
+// fillCharges gives a new wand 3 to 6 charges.
+func fillCharges(it *item) {
+	it.MaxCharges = 3 + rand.Intn(4)
+	it.Charges = it.MaxCharges
+}
+
+func newWandOfLightning() *item {
+	it := newItem(
+		0,
+		0,
+		"-",
+		color.RGBA{
+			R: 255,
+			G: 255,
+			B: 0,
+			A: 255,
+		},
+		"Wand of Lightning",
+		newLightningBoltConsumable(12, 8),
+		nil,
+	)
+	it.Category = categoryWand
+	fillCharges(it)
+	it.Value = 100
+	it.Weight = 7
+	return it
+}
+
+func newWandOfFire() *item {
+	it := newItem(
+		0,
+		0,
+		"-",
+		color.RGBA{
+			R: 255,
+			G: 63,
+			B: 0,
+			A: 255,
+		},
+		"Wand of Fire",
+		newFireballDamageConsumable(8, 2),
+		nil,
+	)
+	it.Category = categoryWand
+	fillCharges(it)
+	it.Value = 120
+	it.Weight = 7
+	return it
+}
+
+func newWandOfSlow() *item {
+	it := newItem(
+		0,
+		0,
+		"-",
+		color.RGBA{
+			R: 191,
+			G: 127,
+			B: 63,
+			A: 255,
+		},
+		"Wand of Slow",
+		newTargetedEffectConsumable(effectSlow, 10, 0),
+		nil,
+	)
+	it.Category = categoryWand
+	fillCharges(it)
+	it.Value = 80
+	it.Weight = 7
+	return it
+}
+
+func newWandOfParalysis() *item {
+	it := newItem(
+		0,
+		0,
+		"-",
+		color.RGBA{
+			R: 255,
+			G: 255,
+			B: 127,
+			A: 255,
+		},
+		"Wand of Paralysis",
+		newTargetedEffectConsumable(effectParalysis, 4, 0),
+		nil,
+	)
+	it.Category = categoryWand
+	fillCharges(it)
+	it.Value = 110
+	it.Weight = 7
+	return it
+}
+
+func newWandOfDigging() *item {
+	it := newItem(
+		0,
+		0,
+		"-",
+		color.RGBA{
+			R: 159,
+			G: 159,
+			B: 159,
+			A: 255,
+		},
+		"Wand of Digging",
+		newDiggingConsumable(8),
+		nil,
+	)
+	it.Category = categoryWand
+	fillCharges(it)
+	it.Value = 90
+	it.Weight = 7
+	return it
+}
+
+func newDagger() *item {
+	it := newItem(
+		0,
+		0,
+		"/",
+		color.RGBA{
+			R: 0,
+			G: 191,
+			B: 255,
+			A: 255,
+		},
+		"Dagger",
+		nil,
+		newWeapon(2, mustParseDice("1d4+2")),
+	)
+	it.Value = 20
+	it.Weight = 10
+	return it
+}
+
+func newSword() *item {
+	it := newItem(
+		0,
+		0,
+		"/",
+		color.RGBA{
+			R: 0,
+			G: 191,
+			B: 255,
+			A: 255,
+		},
+		"Sword",
+		nil,
+		newWeapon(4, mustParseDice("1d8+3")),
+	)
+	it.Value = 60
+	it.Weight = 40
+	return it
+}
+
+func newShortBow() *item {
+	it := newItem(
+		0,
+		0,
+		"}",
+		color.RGBA{
+			R: 191,
+			G: 127,
+			B: 63,
+			A: 255,
+		},
+		"Short Bow",
+		nil,
+		newLauncher(1, ammoArrow),
+	)
+	it.Value = 50
+	it.Weight = 20
+	return it
+}
+
+func newSling() *item {
+	it := newItem(
+		0,
+		0,
+		"}",
+		color.RGBA{
+			R: 159,
+			G: 159,
+			B: 159,
+			A: 255,
+		},
+		"Sling",
+		nil,
+		newLauncher(0, ammoStone),
+	)
+	it.Value = 20
+	it.Weight = 5
+	return it
+}
+
+func newArrow() *item {
+	it := newItem(
+		0,
+		0,
+		")",
+		color.RGBA{
+			R: 191,
+			G: 127,
+			B: 63,
+			A: 255,
+		},
+		"Arrow",
+		nil,
+		newAmmo(ammoArrow, mustParseDice("1d6+1")),
+	)
+	it.Quantity = 8
+	it.Value = 2
+	it.Weight = 1
+	return it
+}
+
+func newSlingStone() *item {
+	it := newItem(
+		0,
+		0,
+		")",
+		color.RGBA{
+			R: 159,
+			G: 159,
+			B: 159,
+			A: 255,
+		},
+		"Sling Stone",
+		nil,
+		newAmmo(ammoStone, mustParseDice("1d4+1")),
+	)
+	it.Quantity = 8
+	it.Value = 1
+	it.Weight = 2
+	return it
+}
+
+func newLeatherArmor() *item {
+	it := newItem(
+		0,
+		0,
+		"[",
+		color.RGBA{
+			R: 139,
+			G: 69,
+			B: 19,
+			A: 255,
+		},
+		"Leather Armor",
+		nil,
+		newEquippable(slotArmor, 0, 1),
+	)
+	it.Value = 30
+	it.Weight = 100
+	return it
+}
+
+func newChainMail() *item {
+	it := newItem(
+		0,
+		0,
+		"[",
+		color.RGBA{
+			R: 139,
+			G: 69,
+			B: 19,
+			A: 255,
+		},
+		"Chain Mail",
+		nil,
+		newEquippable(slotArmor, 0, 3),
+	)
+	it.Value = 80
+	it.Weight = 250
+	return it
+}
+
+func newShield() *item {
+	it := newItem(
+		0,
+		0,
+		")",
+		color.RGBA{
+			R: 139,
+			G: 69,
+			B: 19,
+			A: 255,
+		},
+		"Shield",
+		nil,
+		newEquippable(slotShield, 0, 1),
+	)
+	it.Value = 40
+	it.Weight = 60
+	return it
+}
+
+func newRingOfStrength() *item {
+	it := newItem(
+		0,
+		0,
+		"=",
+		color.RGBA{
+			R: 255,
+			G: 215,
+			B: 0,
+			A: 255,
+		},
+		"Ring of Strength",
+		nil,
+		newEquippable(slotRing, 1, 0),
+	)
+	it.Value = 100
+	it.Weight = 1
+	return it
+}
+
+func newRingOfProtection() *item {
+	it := newItem(
+		0,
+		0,
+		"=",
+		color.RGBA{
+			R: 255,
+			G: 215,
+			B: 0,
+			A: 255,
+		},
+		"Ring of Protection",
+		nil,
+		newEquippable(slotRing, 0, 1),
 	)
+	it.Value = 100
+	it.Weight = 1
+	return it
 }
@@ -0,0 +1,90 @@
+package main
+
+import "fmt"
+
+type equipment struct {
+	*baseComponent
+	Slots map[equipmentSlot]*item
+}
+
+func newEquipment() *equipment {
+	return &equipment{
+		baseComponent: &baseComponent{
+			Parent: nil,
+		},
+		Slots: map[equipmentSlot]*item{},
+	}
+}
+
+func (c *equipment) PowerBonus() int {
+	bonus := 0
+	for _, it := range c.Slots {
+		bonus += it.Equippable.PowerBonus
+	}
+	return bonus
+}
+
+func (c *equipment) DefenseBonus() int {
+	bonus := 0
+	for _, it := range c.Slots {
+		bonus += it.Equippable.DefenseBonus
+	}
+	return bonus
+}
+
+func (c *equipment) IsEquipped(it *item) bool {
+	if it.Equippable == nil {
+		return false
+	}
+	return c.Slots[it.Equippable.Slot] == it
+}
+
+func (c *equipment) Equip(it *item, addMessage bool) {
+	slot := it.Equippable.Slot
+	if current, ok := c.Slots[slot]; ok {
+		c.Unequip(current, addMessage)
+	}
+	c.Slots[slot] = it
+	if addMessage {
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("You equip the %s.", it.DisplayName()), ColorWhite, true)
+	}
+}
+
+func (c *equipment) Unequip(it *item, addMessage bool) {
+	delete(c.Slots, it.Equippable.Slot)
+	if addMessage {
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("You remove the %s.", it.DisplayName()), ColorWhite, true)
+	}
+}
+
+func (c *equipment) ToggleEquip(it *item, addMessage bool) {
+	if c.IsEquipped(it) {
+		c.Unequip(it, addMessage)
+	} else {
+		c.Equip(it, addMessage)
+	}
+}
+
+// CanFire reports why the owner cannot fire its launcher, if anything.
+func (c *equipment) CanFire() error {
+	launcher, ok := c.Slots[slotWeapon]
+	if !ok || launcher.Equippable.Launches == ammoNone {
+		return impossible{"You have nothing to fire with."}
+	}
+	ammo, ok := c.Slots[slotQuiver]
+	if !ok || ammo.Equippable.AmmoKind != launcher.Equippable.Launches {
+		return impossible{fmt.Sprintf("You have no %s quivered.", launcher.Equippable.Launches)}
+	}
+	return nil
+}
+
+// Reload quivers the next carried ammunition of the given kind.
+func (c *equipment) Reload(kind ammoKind) bool {
+	for _, it := range c.Parent.(*actor).Inventory.Items {
+		if it.Equippable != nil && it.Equippable.AmmoKind == kind {
+			c.Equip(it, false)
+			return true
+		}
+	}
+	return false
+}
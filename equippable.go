@@ -0,0 +1,110 @@
+package main
+
+import "fmt"
+
+type equipmentSlot int
+
+const (
+	slotWeapon equipmentSlot = iota
+	slotArmor
+	slotShield
+	slotRing
+	slotQuiver
+)
+
+func (s equipmentSlot) String() string {
+	switch s {
+	case slotWeapon:
+		return "weapon"
+	case slotArmor:
+		return "armor"
+	case slotShield:
+		return "shield"
+	case slotRing:
+		return "ring"
+	case slotQuiver:
+		return "quiver"
+	default:
+		return "unknown"
+	}
+}
+
+type ammoKind int
+
+const (
+	ammoNone ammoKind = iota
+	ammoArrow
+	ammoStone
+)
+
+func (k ammoKind) String() string {
+	switch k {
+	case ammoArrow:
+		return "arrows"
+	case ammoStone:
+		return "sling stones"
+	default:
+		return "ammunition"
+	}
+}
+
+type equippable struct {
+	baseComponent
+	Slot         equipmentSlot
+	PowerBonus   int
+	DefenseBonus int
+	Damage       dice
+	Launches     ammoKind // set on bows and slings
+	AmmoKind     ammoKind // set on the ammunition they fire
+}
+
+func newEquippable(slot equipmentSlot, powerBonus, defenseBonus int) *equippable {
+	return &equippable{
+		baseComponent: baseComponent{
+			Parent: nil,
+		},
+		Slot:         slot,
+		PowerBonus:   powerBonus,
+		DefenseBonus: defenseBonus,
+	}
+}
+
+func newWeapon(powerBonus int, damage dice) *equippable {
+	eq := newEquippable(slotWeapon, powerBonus, 0)
+	eq.Damage = damage
+	return eq
+}
+
+func newLauncher(powerBonus int, launches ammoKind) *equippable {
+	eq := newEquippable(slotWeapon, powerBonus, 0)
+	eq.Launches = launches
+	return eq
+}
+
+func newAmmo(kind ammoKind, damage dice) *equippable {
+	eq := newEquippable(slotQuiver, 0, 0)
+	eq.AmmoKind = kind
+	eq.Damage = damage
+	return eq
+}
+
+func (c *equippable) Describe() []string {
+	lines := []string{fmt.Sprintf("Slot: %s", c.Slot)}
+	if c.PowerBonus != 0 {
+		lines = append(lines, fmt.Sprintf("Power: %+d", c.PowerBonus))
+	}
+	if c.DefenseBonus != 0 {
+		lines = append(lines, fmt.Sprintf("Defense: %+d", c.DefenseBonus))
+	}
+	if c.Launches != ammoNone {
+		lines = append(lines, fmt.Sprintf("Fires %s.", c.Launches))
+	}
+	if !c.Damage.IsZero() {
+		if c.AmmoKind != ammoNone {
+			lines = append(lines, fmt.Sprintf("Damage when fired: %s", c.Damage))
+		} else {
+			lines = append(lines, fmt.Sprintf("Damage: %s", c.Damage))
+		}
+	}
+	return lines
+}
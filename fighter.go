@@ -6,12 +6,72 @@ import (
 	"math"
 )
 
+type damageType int
+
+const (
+	damagePhysical damageType = iota
+	damageFire
+	damageLightning
+	damageCold
+	damagePoison
+)
+
+func (t damageType) String() string {
+	switch t {
+	case damagePhysical:
+		return "physical"
+	case damageFire:
+		return "fire"
+	case damageLightning:
+		return "lightning"
+	case damageCold:
+		return "cold"
+	case damagePoison:
+		return "poison"
+	default:
+		return "unknown"
+	}
+}
+
 type fighter struct {
 	*baseComponent
-	MaxHP   int
-	HP      int
-	Defense int
-	Power   int
+	MaxHP       int
+	HP          int
+	BaseDefense int
+	BasePower   int
+	Evasion     int
+	Damage      dice // unarmed damage, replaced by the wielded weapon's
+	DamageType  damageType
+
+	// Resisted damage types are halved, vulnerable ones doubled.
+	Resistances     map[damageType]bool
+	Vulnerabilities map[damageType]bool
+
+	// LastAttacker dealt the latest damage, nil when it came from hunger,
+	// poison or anything else without an author.
+	LastAttacker *actor
+}
+
+func (c *fighter) AttackDamageType() damageType {
+	if weapon, ok := c.Parent.(*actor).Equipment.Slots[slotWeapon]; ok && !weapon.Equippable.Damage.IsZero() {
+		return damagePhysical
+	}
+	return c.DamageType
+}
+
+func (c *fighter) DamageDice() dice {
+	if weapon, ok := c.Parent.(*actor).Equipment.Slots[slotWeapon]; ok && !weapon.Equippable.Damage.IsZero() {
+		return weapon.Equippable.Damage
+	}
+	return c.Damage
+}
+
+func (c *fighter) Defense() int {
+	return c.BaseDefense + c.Parent.(*actor).Equipment.DefenseBonus()
+}
+
+func (c *fighter) Power() int {
+	return c.BasePower + c.Parent.(*actor).Equipment.PowerBonus()
 }
 
 func (c *fighter) setHP(val int) {
@@ -35,6 +95,14 @@ func (c *fighter) Die() {
 		deathMessageColor = ColorPlayerDie
 	}
 
+	p.Equipment.Slots = map[equipmentSlot]*item{}
+	p.StatusEffects.Effects = []*statusEffect{}
+	p.Inventory.DropAll()
+	if p.Gold > 0 {
+		newGoldPile(p.Gold).Place(p.X, p.Y, c.GameMap())
+		p.Gold = 0
+	}
+
 	p.Char = "%"
 	p.Color = color.RGBA{R: 191, G: 0, B: 0, A: 255}
 	p.BlocksMovement = false
@@ -43,6 +111,10 @@ func (c *fighter) Die() {
 	p.RO = RenderOrder_Corpse
 
 	c.Engine().MessageLog.AddMessage(deathMessage, deathMessageColor, true)
+
+	if player := c.Engine().Player; p != player && c.LastAttacker == player && player.IsHostile(p) {
+		player.Level.AddXP(p.Level.XPGiven)
+	}
 }
 
 func (c *fighter) Heal(amount int) int {
@@ -62,7 +134,44 @@ func (c *fighter) Heal(amount int) int {
 	return amountRecoverd
 }
 
-func (c *fighter) TakeDamage(amount int) {
-	c.HP -= amount
+// Resist returns how much of amount the fighter would actually take from
+// damage of the given type.
+func (c *fighter) Resist(amount int, dt damageType) int {
+	if c.Resistances[dt] {
+		return amount / 2
+	} else if c.Vulnerabilities[dt] {
+		return amount * 2
+	}
+	return amount
+}
+
+func (c *fighter) TakeDamage(amount int, dt damageType, attacker *actor) {
+	p, ok := c.Parent.(*actor)
+	if !ok {
+		return
+	}
+	if d, ok := p.AI.(*dormantEnemy); ok {
+		d.Wake()
+	}
+
+	if c.Resistances[dt] {
+		c.report(fmt.Sprintf("You resist %s.", dt), fmt.Sprintf("The %s resists %s.", p.Name, dt))
+	} else if c.Vulnerabilities[dt] {
+		c.report(fmt.Sprintf("You are vulnerable to %s!", dt), fmt.Sprintf("The %s is vulnerable to %s!", p.Name, dt))
+	}
+
+	c.LastAttacker = attacker
+	c.HP -= c.Resist(amount, dt)
 	c.setHP(c.HP)
 }
+
+// report shows playerMessage when the fighter is the player, and
+// otherMessage when the fighter is someone else in view.
+func (c *fighter) report(playerMessage, otherMessage string) {
+	p := c.Parent.(*actor)
+	if p == c.Engine().Player {
+		c.Engine().MessageLog.AddMessage(playerMessage, ColorWhite, true)
+	} else if c.GameMap().Visible[p.X][p.Y] {
+		c.Engine().MessageLog.AddMessage(otherMessage, ColorWhite, true)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"math"
 	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -16,6 +17,7 @@ type gameMap struct {
 	Visible  [][]bool
 	Explored [][]bool
 	Entities []entity
+	Shops    []*shop
 }
 
 func newGameMap(en *engine, width, height int, entities []entity) *gameMap {
@@ -80,6 +82,16 @@ func (g gameMap) Items() []*item {
 	return es
 }
 
+func (g gameMap) ItemsAt(x, y int) []*item {
+	es := []*item{}
+	for _, it := range g.Items() {
+		if it.X == x && it.Y == y {
+			es = append(es, it)
+		}
+	}
+	return es
+}
+
 func (g gameMap) GetAcotrAtLocation(x, y int) *actor {
 	for _, a := range g.Actors() {
 		if a.X == x && a.Y == y {
@@ -112,16 +124,29 @@ func (g gameMap) Render(screen *ebiten.Image, font font.Face) {
 			text.Draw(screen, t.Char, font, w*10, h*10, color)
 		}
 	}
+	player := g.Engine.Player
+	detecting := player.StatusEffects.Has(effectDetectMonsters)
 	entities := g.Entities
 	sort.Slice(entities, func(i, j int) bool { return entities[i].RenderOrder() < entities[i].RenderOrder() })
 	for _, entity := range entities {
 		e := entity.Entity()
 		if g.IsVisible(e.X, e.Y) {
 			text.Draw(screen, e.Char, font, e.X*10, e.Y*10, e.Color)
+		} else if a, ok := entity.(*actor); ok && detecting && a.IsAlive() && player.IsHostile(a) {
+			text.Draw(screen, e.Char, font, e.X*10, e.Y*10, e.Color)
 		}
 	}
 }
 
+func (g gameMap) ShopAt(x, y int) *shop {
+	for _, s := range g.Shops {
+		if s.Contains(x, y) {
+			return s
+		}
+	}
+	return nil
+}
+
 func (g gameMap) InBounds(x, y int) bool {
 	return 0 <= x && x < g.Width && 0 <= y && y < g.Height
 }
@@ -130,6 +155,35 @@ func (g gameMap) Walkable(x, y int) bool {
 	return g.Tiles[x][y].Walkable
 }
 
+// BlastArea returns the tiles an explosion at (x, y) reaches. It spreads
+// from the impact point over open ground, so walls shelter whatever is
+// behind them.
+func (g gameMap) BlastArea(x, y, radius int) map[[2]int]bool {
+	area := map[[2]int]bool{}
+	if !g.InBounds(x, y) || !g.Walkable(x, y) {
+		return area
+	}
+	reach := float64(radius + 1)
+	area[[2]int{x, y}] = true
+	frontier := [][2]int{{x, y}}
+	for len(frontier) > 0 {
+		tile := frontier[0]
+		frontier = frontier[1:]
+		for _, d := range directions {
+			next := [2]int{tile[0] + d[0], tile[1] + d[1]}
+			if area[next] || !g.InBounds(next[0], next[1]) || !g.Walkable(next[0], next[1]) {
+				continue
+			}
+			if math.Hypot(float64(next[0]-x), float64(next[1]-y)) > reach {
+				continue
+			}
+			area[next] = true
+			frontier = append(frontier, next)
+		}
+	}
+	return area
+}
+
 func (g gameMap) IsVisible(x, y int) bool {
 	return g.Visible[x][y]
 }
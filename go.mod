@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.3.1
+	github.com/mattn/go-runewidth v0.0.13
 	golang.org/x/image v0.0.0-20220321031419-a8550c1d254a
 )
 
@@ -13,7 +14,6 @@ require (
 	github.com/gofrs/flock v0.8.1 // indirect
 	github.com/google/go-cmp v0.5.7 // indirect
 	github.com/jezek/xgb v1.0.0 // indirect
-	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/nametake/golangci-lint-langserver v0.0.6 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/sergi/go-diff v1.1.0 // indirect
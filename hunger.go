@@ -0,0 +1,95 @@
+package main
+
+const (
+	satiationHungry = 200
+	satiationWeak   = 50
+
+	// starvationInterval is how many turns pass between each point of
+	// damage taken while starving.
+	starvationInterval = 5
+)
+
+type hungerState int
+
+const (
+	hungerNormal hungerState = iota
+	hungerSatiated
+	hungerHungry
+	hungerWeak
+	hungerStarving
+)
+
+func (s hungerState) String() string {
+	switch s {
+	case hungerSatiated:
+		return "Satiated"
+	case hungerHungry:
+		return "Hungry"
+	case hungerWeak:
+		return "Weak"
+	case hungerStarving:
+		return "Starving"
+	default:
+		return ""
+	}
+}
+
+type hunger struct {
+	*baseComponent
+	Satiation    int
+	MaxSatiation int
+}
+
+func newHunger(maxSatiation int) *hunger {
+	return &hunger{
+		baseComponent: &baseComponent{
+			Parent: nil,
+		},
+		Satiation:    maxSatiation,
+		MaxSatiation: maxSatiation,
+	}
+}
+
+func (c *hunger) State() hungerState {
+	switch {
+	case c.Satiation == 0:
+		return hungerStarving
+	case c.Satiation <= satiationWeak:
+		return hungerWeak
+	case c.Satiation <= satiationHungry:
+		return hungerHungry
+	case c.Satiation >= c.MaxSatiation*3/4:
+		return hungerSatiated
+	default:
+		return hungerNormal
+	}
+}
+
+func (c *hunger) Eat(amount int) {
+	c.Satiation += amount
+	if c.Satiation > c.MaxSatiation {
+		c.Satiation = c.MaxSatiation
+	}
+}
+
+func (c *hunger) Tick() {
+	before := c.State()
+	if c.Satiation > 0 {
+		c.Satiation -= 1
+	}
+
+	if state := c.State(); state != before {
+		switch state {
+		case hungerHungry:
+			c.Engine().MessageLog.AddMessage("You are getting hungry.", ColorInvalid, true)
+		case hungerWeak:
+			c.Engine().MessageLog.AddMessage("You feel weak from hunger.", ColorInvalid, true)
+		case hungerStarving:
+			c.Engine().MessageLog.AddMessage("You are starving!", ColorPlayerDie, true)
+		}
+	}
+
+	if c.Satiation == 0 && c.Engine().Turn%starvationInterval == 0 {
+		c.Parent.(*actor).Fighter.TakeDamage(1, damagePhysical, nil)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+type itemCategory int
+
+const (
+	categoryNone itemCategory = iota
+	categoryPotion
+	categoryScroll
+	categoryWand
+)
+
+var potionAppearances = []string{
+	"murky", "bubbling", "fizzy", "smoky", "milky", "swirling", "glowing", "cloudy", "oily", "golden",
+}
+
+var wandAppearances = []string{
+	"oak", "bone", "iron", "crystal", "ebony", "copper", "glass", "jade",
+}
+
+var scrollSyllables = []string{
+	"XY", "ZZY", "FOO", "ELB", "KER", "NIH", "VEN", "MOR", "PRA", "TUK", "ZAB", "QUE", "LOK", "DAE",
+}
+
+// itemKnowledge is what the player has learned about potions, scrolls and wands
+// during the run. Their appearances are shuffled from the game seed, so the
+// same seed always dresses up the same items the same way.
+type itemKnowledge struct {
+	Appearances map[string]string // true name -> appearance
+	Known       map[string]bool
+}
+
+// disguisedItems lists the names of the spawnable items that go unidentified
+// until used, per category, in spawn table order. Building each kind once is
+// the only way to learn its name and category.
+func disguisedItems() map[itemCategory][]string {
+	names := map[itemCategory][]string{}
+	seen := map[string]bool{}
+	for _, s := range itemSpawnTable {
+		it := s.New()
+		if it.Category == categoryNone || seen[it.Name] {
+			continue
+		}
+		seen[it.Name] = true
+		names[it.Category] = append(names[it.Category], it.Name)
+	}
+	return names
+}
+
+func newItemKnowledge(seed int64) *itemKnowledge {
+	disguised := disguisedItems()
+	if n, m := len(disguised[categoryPotion]), len(potionAppearances); n > m {
+		panic(fmt.Sprintf("%d potions but only %d potion appearances", n, m))
+	}
+	if n, m := len(disguised[categoryWand]), len(wandAppearances); n > m {
+		panic(fmt.Sprintf("%d wands but only %d wand appearances", n, m))
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	k := &itemKnowledge{
+		Appearances: map[string]string{},
+		Known:       map[string]bool{},
+	}
+
+	potions := r.Perm(len(potionAppearances))
+	for i, name := range disguised[categoryPotion] {
+		k.Appearances[name] = fmt.Sprintf("%s potion", potionAppearances[potions[i]])
+	}
+	wands := r.Perm(len(wandAppearances))
+	for i, name := range disguised[categoryWand] {
+		k.Appearances[name] = fmt.Sprintf("%s wand", wandAppearances[wands[i]])
+	}
+	labels := map[string]bool{}
+	for _, name := range disguised[categoryScroll] {
+		label := ""
+		for label == "" || labels[label] {
+			syllables := make([]string, 2+r.Intn(2))
+			for i := range syllables {
+				syllables[i] = scrollSyllables[r.Intn(len(scrollSyllables))]
+			}
+			label = strings.Join(syllables, "")
+		}
+		labels[label] = true
+		k.Appearances[name] = fmt.Sprintf("scroll labeled %s", label)
+	}
+	return k
+}
+
+func (k *itemKnowledge) IsKnown(it *item) bool {
+	if _, ok := k.Appearances[it.Name]; !ok {
+		return true
+	}
+	return k.Known[it.Name]
+}
+
+func (k *itemKnowledge) Name(it *item) string {
+	if k.IsKnown(it) {
+		return it.Name
+	}
+	return k.Appearances[it.Name]
+}
+
+// Identify marks the item's kind as known and reports whether it wasn't
+// already.
+func (k *itemKnowledge) Identify(it *item) bool {
+	if k.IsKnown(it) {
+		return false
+	}
+	k.Known[it.Name] = true
+	return true
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"image/color"
 	"math"
+	"math/rand"
+	"sort"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -52,6 +54,9 @@ var (
 	}
 )
 
+// menuLetters is how many entries a menu can offer, one per letter key.
+const menuLetters = 26
+
 type eventHandler interface {
 	HandleEvent(keys []ebiten.Key) (eventHandler, error)
 	OnRender(screen *ebiten.Image)
@@ -82,6 +87,9 @@ func (e *eventHandlerBase) HandleEvent(keys []ebiten.Key) (eventHandler, error)
 				},
 			}, nil
 		}
+		if e.engine.Player.Level.RequiresLevelUp() {
+			return newLevelUpEventHandler(e.engine), nil
+		}
 	}
 	return e, nil
 }
@@ -92,7 +100,14 @@ func (e *eventHandlerBase) EvKeyDown(keys []ebiten.Key) interface{} {
 		if !repeatingKeyPressed(p) {
 			continue
 		}
+		if p != ebiten.KeyEscape && player.StatusEffects.Has(effectParalysis) {
+			e.engine.MessageLog.AddMessage("You are paralyzed!", ColorImpossible, true)
+			return waitAction{}
+		}
 		if d, ok := moveKeys[p]; ok {
+			if player.StatusEffects.Has(effectConfusion) {
+				d = directions[rand.Intn(len(directions))]
+			}
 			return bumpAction{
 				actionWithDirection{
 					baseAction: baseAction{
@@ -111,11 +126,36 @@ func (e *eventHandlerBase) EvKeyDown(keys []ebiten.Key) interface{} {
 			case ebiten.KeyV:
 				return newHistoryViewer(e.engine)
 			case ebiten.KeyG:
+				if items := e.engine.GameMap.ItemsAt(player.X, player.Y); len(items) > 1 {
+					return newPickupMenuHandler(e.engine, items)
+				}
 				return newPickupAction(e.engine.Player)
 			case ebiten.KeyI:
-				return newInventoryActivateHandler(e.engine)
+				return newInventoryScreenHandler(e.engine)
 			case ebiten.KeyD:
 				return newInventoryDropHandler(e.engine)
+			case ebiten.KeyE:
+				return newEatCorpseAction(player)
+			case ebiten.KeyS:
+				s := e.engine.GameMap.ShopAt(player.X, player.Y)
+				if s == nil || !s.IsOpen() {
+					e.engine.MessageLog.AddMessage("There is no shop here.", ColorImpossible, true)
+					return noneAction{}
+				}
+				return newShopEventHandler(e.engine, s)
+			case ebiten.KeyT:
+				return newInventoryThrowHandler(e.engine)
+			case ebiten.KeyF:
+				if err := player.Equipment.CanFire(); err != nil {
+					e.engine.MessageLog.AddMessage(err.Error(), ColorImpossible, true)
+					return noneAction{}
+				}
+				e.engine.MessageLog.AddMessage("Select a target location.", ColorNeedsTarget, true)
+				return newSingleRangedAttackHandler(e.engine, true, func(x, y int) action {
+					return newFireAction(player, [2]int{x, y})
+				})
+			case ebiten.KeyC:
+				return newCharacterScreenEventHandler(e.engine)
 			case ebiten.KeySlash:
 				return &lookHandler{selectIndexHandler: newSelectIndexHandler(e.engine)}
 			default:
@@ -142,6 +182,7 @@ func (e *eventHandlerBase) HandleAction(act action) (bool, error) {
 		if err := e.engine.HandleEnemyTurns(); err != nil {
 			return false, err
 		}
+		e.engine.EndTurn()
 		e.engine.UpdateFov()
 		return true, nil
 	}
@@ -199,6 +240,300 @@ func (e *askUserEventHandler) OnExit() action {
 	return &mainGameEventHandler{eventHandlerBase{engine: e.engine}}
 }
 
+type characterScreenEventHandler struct {
+	askUserEventHandler
+	Title  string
+	Window *ebiten.Image
+}
+
+func newCharacterScreenEventHandler(e *engine) *characterScreenEventHandler {
+	return &characterScreenEventHandler{
+		askUserEventHandler: askUserEventHandler{
+			eventHandlerBase: eventHandlerBase{
+				engine: e,
+			},
+		},
+		Title: "Character Information",
+	}
+}
+
+func (e *characterScreenEventHandler) OnRender(screen *ebiten.Image) {
+	e.askUserEventHandler.OnRender(screen)
+
+	player := e.engine.Player
+	lines := []string{
+		fmt.Sprintf("Level: %d", player.Level.CurrentLevel),
+		fmt.Sprintf("XP: %d", player.Level.CurrentXP),
+		fmt.Sprintf("XP for next Level: %d", player.Level.ExperienceToNextLevel()),
+		fmt.Sprintf("HP: %d/%d", player.Fighter.HP, player.Fighter.MaxHP),
+		fmt.Sprintf("Attack: %d", player.Fighter.Power()),
+		fmt.Sprintf("Defense: %d", player.Fighter.Defense()),
+		fmt.Sprintf("Evasion: %d", player.Fighter.Evasion),
+		fmt.Sprintf("Damage: %s", player.Fighter.DamageDice()),
+	}
+
+	width := len(e.Title)*10 + 40
+	height := len(lines)*10 + 20
+	if e.Window == nil {
+		e.Window = ebiten.NewImage(width, height)
+	}
+	fillWindow(e.Window, width, height, e.Title, e.engine.Font, ColorBlack, ColorWhite)
+
+	for i, line := range lines {
+		text.Draw(e.Window, line, e.engine.Font, 10, 20+i*10, ColorWhite)
+	}
+	op := &ebiten.DrawImageOptions{}
+	x := 0.0
+	y := 0.0
+	if player.X <= 30 {
+		x = 400
+	}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(e.Window, op)
+}
+
+type levelUpEventHandler struct {
+	askUserEventHandler
+	Title  string
+	Window *ebiten.Image
+}
+
+func newLevelUpEventHandler(e *engine) *levelUpEventHandler {
+	return &levelUpEventHandler{
+		askUserEventHandler: askUserEventHandler{
+			eventHandlerBase: eventHandlerBase{
+				engine: e,
+			},
+		},
+		Title: "Level Up",
+	}
+}
+
+func (e *levelUpEventHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
+	state := e.EvKeyDown(keys)
+	if h, ok := state.(eventHandler); ok {
+		return h, nil
+	}
+	return e, nil
+}
+
+func (e *levelUpEventHandler) EvKeyDown(keys []ebiten.Key) interface{} {
+	lvl := e.engine.Player.Level
+	for _, p := range keys {
+		if !repeatingKeyPressed(p) {
+			continue
+		}
+		switch p {
+		case ebiten.KeyA:
+			lvl.IncreaseMaxHP(20)
+		case ebiten.KeyB:
+			lvl.IncreasePower(1)
+		case ebiten.KeyC:
+			lvl.IncreaseDefense(1)
+		default:
+			e.engine.MessageLog.AddMessage("Invalid entry.", ColorInvalid, true)
+			return noneAction{}
+		}
+		if lvl.RequiresLevelUp() {
+			return newLevelUpEventHandler(e.engine)
+		}
+		return &mainGameEventHandler{eventHandlerBase{engine: e.engine}}
+	}
+	return noneAction{}
+}
+
+func (e *levelUpEventHandler) OnRender(screen *ebiten.Image) {
+	e.askUserEventHandler.OnRender(screen)
+
+	fig := e.engine.Player.Fighter
+	lines := []string{
+		"Congratulations! You level up!",
+		"Select an attribute to increase.",
+		fmt.Sprintf("a) Constitution (+20 HP, from %d)", fig.MaxHP),
+		fmt.Sprintf("b) Strength (+1 attack, from %d)", fig.Power()),
+		fmt.Sprintf("c) Agility (+1 defense, from %d)", fig.Defense()),
+	}
+
+	width := 0
+	for _, line := range lines {
+		if w := len(line)*10 + 20; w > width {
+			width = w
+		}
+	}
+	height := len(lines)*10 + 20
+	if e.Window == nil {
+		e.Window = ebiten.NewImage(width, height)
+	}
+	fillWindow(e.Window, width, height, e.Title, e.engine.Font, ColorBlack, ColorWhite)
+
+	for i, line := range lines {
+		text.Draw(e.Window, line, e.engine.Font, 10, 20+i*10, ColorWhite)
+	}
+	op := &ebiten.DrawImageOptions{}
+	x := 0.0
+	y := 0.0
+	if e.engine.Player.X <= 30 {
+		x = 400
+	}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(e.Window, op)
+}
+
+// inventoryLine describes a carried item the way inventory menus list it.
+func inventoryLine(owner *actor, it *item) string {
+	line := it.DisplayName()
+	if it.Quantity > 1 {
+		line = fmt.Sprintf("%s (x%d)", line, it.Quantity)
+	}
+	if it.IsEmpty() {
+		line = fmt.Sprintf("%s (empty)", line)
+	} else if it.MaxCharges > 0 {
+		line = fmt.Sprintf("%s (%d charges)", line, it.Charges)
+	}
+	if owner.Equipment.IsEquipped(it) {
+		line = fmt.Sprintf("%s (E)", line)
+	}
+	return line
+}
+
+// pickupMenuHandler lets the player choose which of the items lying on the
+// same tile to pick up.
+type pickupMenuHandler struct {
+	askUserEventHandler
+	Items    []*item
+	Selected map[*item]bool
+	Window   *ebiten.Image
+}
+
+func newPickupMenuHandler(e *engine, items []*item) *pickupMenuHandler {
+	return &pickupMenuHandler{
+		askUserEventHandler: askUserEventHandler{
+			eventHandlerBase: eventHandlerBase{
+				engine: e,
+			},
+		},
+		Items:    items,
+		Selected: map[*item]bool{},
+	}
+}
+
+func (e *pickupMenuHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
+	state := e.EvKeyDown(keys)
+	if h, ok := state.(eventHandler); ok {
+		return h, nil
+	}
+	if a, ok := state.(action); ok {
+		h, err := e.HandleAction(a)
+		if err != nil {
+			return nil, err
+		}
+		if h != nil {
+			return h, nil
+		}
+	}
+	return e, nil
+}
+
+func (e *pickupMenuHandler) EvKeyDown(keys []ebiten.Key) interface{} {
+	for _, p := range keys {
+		if !repeatingKeyPressed(p) {
+			continue
+		}
+		idx := p - ebiten.KeyA
+		if 0 <= idx && idx < menuLetters {
+			if int(idx) >= len(e.Items) {
+				e.engine.MessageLog.AddMessage("Invalid entry.", ColorInvalid, true)
+				return noneAction{}
+			}
+			it := e.Items[idx]
+			e.Selected[it] = !e.Selected[it]
+			return noneAction{}
+		}
+		switch p {
+		case ebiten.KeyComma:
+			// Like autopickup, "all" leaves the wares of a shop alone.
+			items := []*item{}
+			for _, it := range e.Items {
+				if it.Shop == nil {
+					items = append(items, it)
+				}
+			}
+			if len(items) == 0 {
+				e.engine.MessageLog.AddMessage("Everything here is for sale.", ColorImpossible, true)
+				return noneAction{}
+			}
+			return newPickupItemsAction(e.engine.Player, items)
+		case ebiten.KeyEnter:
+			items := []*item{}
+			for _, it := range e.Items {
+				if e.Selected[it] {
+					items = append(items, it)
+				}
+			}
+			if len(items) == 0 {
+				return e.OnExit()
+			}
+			return newPickupItemsAction(e.engine.Player, items)
+		}
+	}
+	return e.askUserEventHandler.EvKeyDown(keys)
+}
+
+func (e *pickupMenuHandler) OnRender(screen *ebiten.Image) {
+	e.askUserEventHandler.OnRender(screen)
+
+	title := "Pick up which items?"
+	footer := "[,] all  [Enter] selected"
+	items := e.Items
+	if len(items) > menuLetters {
+		footer = fmt.Sprintf("%s  (%d more)", footer, len(items)-menuLetters)
+		items = items[:menuLetters]
+	}
+	lines := make([]string, 0, len(items))
+	for i, it := range items {
+		mark := " "
+		if e.Selected[it] {
+			mark = "+"
+		}
+		name := it.DisplayName()
+		if it.Quantity > 1 {
+			name = fmt.Sprintf("%s (x%d)", name, it.Quantity)
+		}
+		lines = append(lines, fmt.Sprintf("(%s) %s %s", string(rune(0x41+i)), mark, name))
+	}
+
+	width := len(title)*10 + 40
+	for _, line := range append([]string{footer}, lines...) {
+		if w := len(line)*10 + 20; w > width {
+			width = w
+		}
+	}
+	height := len(lines)*10 + 40
+	if e.Window != nil {
+		if ww, wh := e.Window.Size(); ww != width || wh != height {
+			e.Window = nil
+		}
+	}
+	if e.Window == nil {
+		e.Window = ebiten.NewImage(width, height)
+	}
+	fillWindow(e.Window, width, height, title, e.engine.Font, ColorBlack, ColorWhite)
+
+	for i, line := range lines {
+		text.Draw(e.Window, line, e.engine.Font, 10, 20+i*10, e.Items[i].DisplayColor())
+	}
+	text.Draw(e.Window, footer, e.engine.Font, 10, height-10, ColorMenuText)
+
+	op := &ebiten.DrawImageOptions{}
+	x := 0.0
+	y := 0.0
+	if e.engine.Player.X <= 30 {
+		x = 400
+	}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(e.Window, op)
+}
+
 type inventoryEventHandler struct {
 	askUserEventHandler
 	Title  string
@@ -208,12 +543,40 @@ type inventoryEventHandler struct {
 func (e *inventoryEventHandler) OnRender(screen *ebiten.Image) {
 	e.askUserEventHandler.OnRender(screen)
 
-	numberOfItemsInInventory := len(e.engine.Player.Inventory.Items)
-	height := numberOfItemsInInventory*10 + 20
+	player := e.engine.Player
+	numberOfItemsInInventory := len(player.Inventory.Items)
+	height := numberOfItemsInInventory*10 + 30
+
+	lines := make([]string, 0, numberOfItemsInInventory)
+	colors := make([]color.RGBA, 0, numberOfItemsInInventory)
+	for i, it := range player.Inventory.Items {
+		line := fmt.Sprintf("(%s) %s", string(rune(0x41+i)), inventoryLine(player, it))
+		lines = append(lines, line)
+		colors = append(colors, it.DisplayColor())
+	}
 
 	width := len(e.Title)*10 + 40
-	if height <= 30 {
-		height = 30
+	for _, line := range lines {
+		if w := len(line)*10 + 20; w > width {
+			width = w
+		}
+	}
+	inv := player.Inventory
+	status := fmt.Sprintf("Weight: %s/%s", formatWeight(inv.Weight()), formatWeight(inv.CarryLimit()))
+	if inv.IsBurdened() {
+		status = fmt.Sprintf("%s (Burdened)", status)
+	}
+	if w := len(status)*10 + 20; w > width {
+		width = w
+	}
+
+	if height <= 40 {
+		height = 40
+	}
+	if e.Window != nil {
+		if ww, wh := e.Window.Size(); ww != width || wh != height {
+			e.Window = nil
+		}
 	}
 	if e.Window == nil {
 		e.Window = ebiten.NewImage(width, height)
@@ -221,12 +584,17 @@ func (e *inventoryEventHandler) OnRender(screen *ebiten.Image) {
 	fillWindow(e.Window, width, height, e.Title, e.engine.Font, ColorBlack, ColorWhite)
 
 	if numberOfItemsInInventory > 0 {
-		for i, it := range e.engine.Player.Inventory.Items {
-			text.Draw(e.Window, fmt.Sprintf("(%s) %s", string(0x41+i), it.Name), e.engine.Font, 10, 20+i*10, ColorWhite)
+		for i, line := range lines {
+			text.Draw(e.Window, line, e.engine.Font, 10, 20+i*10, colors[i])
 		}
 	} else {
 		text.Draw(e.Window, "(Empty)", e.engine.Font, 10, 20, ColorWhite)
 	}
+	statusColor := ColorMenuTitle
+	if inv.IsBurdened() {
+		statusColor = ColorInvalid
+	}
+	text.Draw(e.Window, status, e.engine.Font, 10, height-10, statusColor)
 	op := &ebiten.DrawImageOptions{}
 	x := 0.0
 	y := 0.0
@@ -237,24 +605,32 @@ func (e *inventoryEventHandler) OnRender(screen *ebiten.Image) {
 	screen.DrawImage(e.Window, op)
 }
 
-type inventoryActivateHandler struct {
-	inventoryEventHandler
+type shopEventHandler struct {
+	askUserEventHandler
+	Shop    *shop
+	Selling bool
+	Window  *ebiten.Image
 }
 
-func newInventoryActivateHandler(e *engine) *inventoryActivateHandler {
-	return &inventoryActivateHandler{
-		inventoryEventHandler: inventoryEventHandler{
-			askUserEventHandler: askUserEventHandler{
-				eventHandlerBase: eventHandlerBase{
-					engine: e,
-				},
+func newShopEventHandler(e *engine, s *shop) *shopEventHandler {
+	return &shopEventHandler{
+		askUserEventHandler: askUserEventHandler{
+			eventHandlerBase: eventHandlerBase{
+				engine: e,
 			},
-			Title: "Select an item to use",
 		},
+		Shop: s,
 	}
 }
 
-func (e *inventoryActivateHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
+func (e *shopEventHandler) Items() []*item {
+	if e.Selling {
+		return e.engine.Player.Inventory.Items
+	}
+	return e.Shop.Stock()
+}
+
+func (e *shopEventHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
 	state := e.EvKeyDown(keys)
 	if h, ok := state.(eventHandler); ok {
 		return h, nil
@@ -265,31 +641,356 @@ func (e *inventoryActivateHandler) HandleEvent(keys []ebiten.Key) (eventHandler,
 			return nil, err
 		}
 	}
+	if !e.Shop.IsOpen() {
+		return &mainGameEventHandler{eventHandlerBase{engine: e.engine}}, nil
+	}
 	return e, nil
 }
 
-func (e *inventoryActivateHandler) EvKeyDown(keys []ebiten.Key) interface{} {
+func (e *shopEventHandler) EvKeyDown(keys []ebiten.Key) interface{} {
 	player := e.engine.Player
 	for _, p := range keys {
 		if !repeatingKeyPressed(p) {
 			continue
 		}
+		if p == ebiten.KeyTab {
+			e.Selling = !e.Selling
+			return noneAction{}
+		}
 		idx := p - ebiten.KeyA
 
-		if 0 <= idx && idx <= 26 {
-			if len(player.Inventory.Items) > int(idx) {
-				return e.OnItemSelected(player.Inventory.Items[idx])
-			} else {
+		if 0 <= idx && idx < menuLetters {
+			items := e.Items()
+			if len(items) <= int(idx) {
 				e.engine.MessageLog.AddMessage("Invalid entry.", ColorInvalid, true)
 				return noneAction{}
 			}
+			if e.Selling {
+				return newSellAction(player, e.Shop, items[idx])
+			}
+			return newBuyAction(player, e.Shop, items[idx])
 		}
 	}
 	return e.askUserEventHandler.EvKeyDown(keys)
 }
 
-func (e inventoryActivateHandler) OnItemSelected(it *item) action {
-	return it.Consumable.GetAction(e.engine.Player)
+func (e *shopEventHandler) OnRender(screen *ebiten.Image) {
+	e.askUserEventHandler.OnRender(screen)
+
+	player := e.engine.Player
+	title := fmt.Sprintf("Buy (Tab: sell) - %d gold", player.Gold)
+	if e.Selling {
+		title = fmt.Sprintf("Sell (Tab: buy) - %d gold", player.Gold)
+	}
+
+	items := e.Items()
+	more := 0
+	if len(items) > menuLetters {
+		more = len(items) - menuLetters
+		items = items[:menuLetters]
+	}
+	lines := make([]string, 0, len(items)+1)
+	colors := make([]color.RGBA, 0, len(items)+1)
+	for i, it := range items {
+		price := e.Shop.BuyPrice(it)
+		if e.Selling {
+			price = e.Shop.SellPrice(it)
+		}
+		line := fmt.Sprintf("(%s) %s", string(rune(0x41+i)), it.DisplayName())
+		if it.Quantity > 1 {
+			line = fmt.Sprintf("%s (x%d)", line, it.Quantity)
+		}
+		lines = append(lines, fmt.Sprintf("%s - %d gold", line, price))
+		colors = append(colors, it.DisplayColor())
+	}
+	if more > 0 {
+		lines = append(lines, fmt.Sprintf("(%d more)", more))
+		colors = append(colors, ColorMenuText)
+	}
+
+	width := len(title)*10 + 40
+	for _, line := range lines {
+		if w := len(line)*10 + 20; w > width {
+			width = w
+		}
+	}
+	height := len(lines)*10 + 20
+	if height <= 30 {
+		height = 30
+	}
+	if e.Window != nil {
+		if ww, wh := e.Window.Size(); ww != width || wh != height {
+			e.Window = nil
+		}
+	}
+	if e.Window == nil {
+		e.Window = ebiten.NewImage(width, height)
+	}
+	fillWindow(e.Window, width, height, title, e.engine.Font, ColorBlack, ColorWhite)
+
+	if len(lines) > 0 {
+		for i, line := range lines {
+			text.Draw(e.Window, line, e.engine.Font, 10, 20+i*10, colors[i])
+		}
+	} else {
+		text.Draw(e.Window, "(Empty)", e.engine.Font, 10, 20, ColorWhite)
+	}
+	op := &ebiten.DrawImageOptions{}
+	x := 0.0
+	y := 0.0
+	if player.X <= 30 {
+		x = 400
+	}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(e.Window, op)
+}
+
+// activateItem uses a consumable or toggles a piece of equipment.
+func activateItem(e *engine, it *item) action {
+	if it.IsEmpty() {
+		e.MessageLog.AddMessage(fmt.Sprintf("The %s is empty.", it.DisplayName()), ColorImpossible, true)
+		return noneAction{}
+	}
+	if it.Consumable != nil {
+		return it.Consumable.GetAction(e.Player)
+	} else if it.Equippable != nil {
+		return newEquipAction(e.Player, it)
+	}
+	return noneAction{}
+}
+
+type inventorySort int
+
+const (
+	sortByType inventorySort = iota
+	sortByName
+)
+
+func (s inventorySort) String() string {
+	switch s {
+	case sortByName:
+		return "name"
+	default:
+		return "type"
+	}
+}
+
+// typeRank orders items when the inventory screen sorts them by type.
+func typeRank(it *item) int {
+	switch {
+	case it.Category == categoryPotion:
+		return 0
+	case it.Category == categoryScroll:
+		return 1
+	case it.Category == categoryWand:
+		return 2
+	case it.Consumable != nil:
+		return 3
+	case it.Equippable != nil && it.Equippable.AmmoKind != ammoNone:
+		return 5
+	case it.Equippable != nil:
+		return 4
+	default:
+		return 6
+	}
+}
+
+var inventoryFilters = []struct {
+	Name  string
+	Match func(it *item) bool
+}{
+	{"All", func(it *item) bool { return true }},
+	{"Potions", func(it *item) bool { return it.Category == categoryPotion }},
+	{"Scrolls", func(it *item) bool { return it.Category == categoryScroll }},
+	{"Wands", func(it *item) bool { return it.Category == categoryWand }},
+	{"Equipment", func(it *item) bool { return it.Equippable != nil }},
+}
+
+const inventoryScreenRows = 30
+
+// inventoryScreenHandler is the full inventory screen. It lists the carried
+// items sorted and filtered, shows the details of the one under the cursor
+// and lets the player use, equip, drop or throw it.
+type inventoryScreenHandler struct {
+	askUserEventHandler
+	Sort   inventorySort
+	Filter int
+	Cursor int
+	Offset int
+	Window *ebiten.Image
+}
+
+func newInventoryScreenHandler(e *engine) *inventoryScreenHandler {
+	return &inventoryScreenHandler{
+		askUserEventHandler: askUserEventHandler{
+			eventHandlerBase: eventHandlerBase{
+				engine: e,
+			},
+		},
+	}
+}
+
+func (e *inventoryScreenHandler) Items() []*item {
+	filter := inventoryFilters[e.Filter]
+	items := []*item{}
+	for _, it := range e.engine.Player.Inventory.Items {
+		if filter.Match(it) {
+			items = append(items, it)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if e.Sort == sortByType {
+			if ri, rj := typeRank(items[i]), typeRank(items[j]); ri != rj {
+				return ri < rj
+			}
+		}
+		return items[i].DisplayName() < items[j].DisplayName()
+	})
+	return items
+}
+
+// clampCursor keeps the cursor on the list and scrolls it into view.
+func (e *inventoryScreenHandler) clampCursor(length int) {
+	if e.Cursor >= length {
+		e.Cursor = length - 1
+	}
+	if e.Cursor < 0 {
+		e.Cursor = 0
+	}
+	if e.Cursor < e.Offset {
+		e.Offset = e.Cursor
+	} else if e.Cursor >= e.Offset+inventoryScreenRows {
+		e.Offset = e.Cursor - inventoryScreenRows + 1
+	}
+}
+
+func (e *inventoryScreenHandler) Selected() *item {
+	items := e.Items()
+	e.clampCursor(len(items))
+	if len(items) == 0 {
+		return nil
+	}
+	return items[e.Cursor]
+}
+
+func (e *inventoryScreenHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
+	state := e.EvKeyDown(keys)
+	if h, ok := state.(eventHandler); ok {
+		return h, nil
+	}
+	if a, ok := state.(action); ok {
+		_, err := e.HandleAction(a)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func (e *inventoryScreenHandler) EvKeyDown(keys []ebiten.Key) interface{} {
+	player := e.engine.Player
+	for _, p := range keys {
+		if !repeatingKeyPressed(p) {
+			continue
+		}
+		if adjust, ok := cursorYKeys[p]; ok {
+			e.Cursor += adjust
+			e.clampCursor(len(e.Items()))
+			return noneAction{}
+		}
+		switch p {
+		case ebiten.KeyTab:
+			e.Filter = (e.Filter + 1) % len(inventoryFilters)
+			e.Cursor, e.Offset = 0, 0
+			return noneAction{}
+		case ebiten.KeyS:
+			e.Sort = (e.Sort + 1) % 2
+			return noneAction{}
+		case ebiten.KeyEnter, ebiten.KeyU, ebiten.KeyE, ebiten.KeyD, ebiten.KeyT:
+		default:
+			return e.askUserEventHandler.EvKeyDown([]ebiten.Key{p})
+		}
+
+		it := e.Selected()
+		if it == nil {
+			e.engine.MessageLog.AddMessage("Invalid entry.", ColorInvalid, true)
+			return noneAction{}
+		}
+		switch p {
+		case ebiten.KeyE:
+			if it.Equippable == nil {
+				e.engine.MessageLog.AddMessage(fmt.Sprintf("You cannot equip the %s.", it.DisplayName()), ColorImpossible, true)
+				return noneAction{}
+			}
+			return newEquipAction(player, it)
+		case ebiten.KeyD:
+			return &dropItem{
+				itemAction: *newItemAction(player, it, nil),
+			}
+		case ebiten.KeyT:
+			return throwItem(e.engine, it)
+		default:
+			return activateItem(e.engine, it)
+		}
+	}
+	return noneAction{}
+}
+
+func (e *inventoryScreenHandler) OnRender(screen *ebiten.Image) {
+	e.askUserEventHandler.OnRender(screen)
+
+	player := e.engine.Player
+	inv := player.Inventory
+	items := e.Items()
+	e.clampCursor(len(items))
+
+	width, height := screen.Size()
+	if e.Window == nil {
+		e.Window = ebiten.NewImage(width-40, height-40)
+	}
+	ww, wh := e.Window.Size()
+	title := fmt.Sprintf("Inventory: %s, by %s", inventoryFilters[e.Filter].Name, e.Sort)
+	fillWindow(e.Window, ww, wh, title, e.engine.Font, ColorBlack, ColorWhite)
+
+	if len(items) == 0 {
+		text.Draw(e.Window, "(Empty)", e.engine.Font, 10, 20, ColorWhite)
+	}
+	for i := e.Offset; i < len(items) && i < e.Offset+inventoryScreenRows; i++ {
+		line := fmt.Sprintf("  %s", inventoryLine(player, items[i]))
+		if i == e.Cursor {
+			line = fmt.Sprintf("> %s", inventoryLine(player, items[i]))
+		}
+		text.Draw(e.Window, line, e.engine.Font, 10, 20+(i-e.Offset)*10, items[i].DisplayColor())
+	}
+
+	if it := e.Selected(); it != nil {
+		paneX := ww/2 + 10
+		text.Draw(e.Window, it.DisplayName(), e.engine.Font, paneX, 20, it.DisplayColor())
+		y := 40
+		for _, line := range it.Describe() {
+			for _, wrapped := range strings.Split(runewidth.Wrap(line, (ww/2-30)/10), "\n") {
+				text.Draw(e.Window, wrapped, e.engine.Font, paneX, y, ColorWhite)
+				y += 10
+			}
+		}
+	}
+
+	status := fmt.Sprintf("Weight: %s/%s", formatWeight(inv.Weight()), formatWeight(inv.CarryLimit()))
+	statusColor := ColorMenuTitle
+	if inv.IsBurdened() {
+		status = fmt.Sprintf("%s (Burdened)", status)
+		statusColor = ColorInvalid
+	}
+	if len(items) > inventoryScreenRows {
+		last := int(math.Min(float64(e.Offset+inventoryScreenRows), float64(len(items))))
+		status = fmt.Sprintf("%s  Items %d-%d of %d", status, e.Offset+1, last, len(items))
+	}
+	text.Draw(e.Window, status, e.engine.Font, 10, wh-20, statusColor)
+	text.Draw(e.Window, "[Enter/U] use [E] equip [D] drop [T] throw [Tab] filter [S] sort", e.engine.Font, 10, wh-10, ColorMenuText)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64((width-ww)/2), float64((height-wh)/2))
+	screen.DrawImage(e.Window, op)
 }
 
 type inventoryDropHandler struct {
@@ -331,7 +1032,7 @@ func (e *inventoryDropHandler) EvKeyDown(keys []ebiten.Key) interface{} {
 		}
 		idx := p - ebiten.KeyA
 
-		if 0 <= idx && idx <= 26 {
+		if 0 <= idx && idx < menuLetters {
 			if len(player.Inventory.Items) > int(idx) {
 				return e.OnItemSelected(player.Inventory.Items[idx])
 			} else {
@@ -349,6 +1050,77 @@ func (e inventoryDropHandler) OnItemSelected(it *item) action {
 	}
 }
 
+func newInventoryThrowHandler(e *engine) *inventorySelectHandler {
+	return newInventorySelectHandler(e, "Select an item to throw", func(it *item) action {
+		return throwItem(e, it)
+	})
+}
+
+// throwItem asks where to throw the item.
+func throwItem(e *engine, it *item) action {
+	e.MessageLog.AddMessage("Select a target location.", ColorNeedsTarget, true)
+	return newSingleRangedAttackHandler(e, true, func(x, y int) action {
+		return newThrowAction(e.Player, it, [2]int{x, y})
+	})
+}
+
+type inventorySelectHandler struct {
+	inventoryEventHandler
+	Callback func(it *item) action
+}
+
+func newInventorySelectHandler(e *engine, title string, callback func(it *item) action) *inventorySelectHandler {
+	return &inventorySelectHandler{
+		inventoryEventHandler: inventoryEventHandler{
+			askUserEventHandler: askUserEventHandler{
+				eventHandlerBase: eventHandlerBase{
+					engine: e,
+				},
+			},
+			Title: title,
+		},
+		Callback: callback,
+	}
+}
+
+func (e *inventorySelectHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
+	state := e.EvKeyDown(keys)
+	if h, ok := state.(eventHandler); ok {
+		return h, nil
+	}
+	if a, ok := state.(action); ok {
+		_, err := e.HandleAction(a)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func (e *inventorySelectHandler) EvKeyDown(keys []ebiten.Key) interface{} {
+	player := e.engine.Player
+	for _, p := range keys {
+		if !repeatingKeyPressed(p) {
+			continue
+		}
+		idx := p - ebiten.KeyA
+
+		if 0 <= idx && idx < menuLetters {
+			if len(player.Inventory.Items) > int(idx) {
+				return e.OnItemSelected(player.Inventory.Items[idx])
+			} else {
+				e.engine.MessageLog.AddMessage("Invalid entry.", ColorInvalid, true)
+				return noneAction{}
+			}
+		}
+	}
+	return e.askUserEventHandler.EvKeyDown(keys)
+}
+
+func (e inventorySelectHandler) OnItemSelected(it *item) action {
+	return e.Callback(it)
+}
+
 type selectIndexHandler struct {
 	askUserEventHandler
 	Player *actor
@@ -374,6 +1146,98 @@ func (e *selectIndexHandler) OnRender(screen *ebiten.Image) {
 	text.Draw(screen, string([]rune{0xdb}), e.engine.Font, x*10, y*10, ColorSelect)
 }
 
+// RenderLineOfFire draws the path a projectile would take from the player to
+// the cursor and warns when something is in the way before the target.
+func (e *selectIndexHandler) RenderLineOfFire(screen *ebiten.Image) {
+	gm := e.engine.GameMap
+	x, y := e.engine.MouseLocation[0], e.engine.MouseLocation[1]
+	if x == e.Player.X && y == e.Player.Y {
+		return
+	}
+
+	line := [][2]int{}
+	for tup := range bresenham(e.Player.X, e.Player.Y, x, y) {
+		line = append(line, tup)
+	}
+	// Only what the player knows about can block the preview: explored walls
+	// and visible actors short of the target.
+	blocked := -1
+	warning := ""
+	for i, tup := range line[1:] {
+		if !gm.InBounds(tup[0], tup[1]) {
+			break
+		}
+		if gm.IsExplored(tup[0], tup[1]) && !gm.Walkable(tup[0], tup[1]) {
+			blocked, warning = i, "A wall is in the way!"
+			break
+		}
+		if i == len(line)-2 {
+			break
+		}
+		if a := gm.GetAcotrAtLocation(tup[0], tup[1]); a != nil && gm.IsVisible(tup[0], tup[1]) {
+			blocked, warning = i, fmt.Sprintf("The %s is in the way!", a.Name)
+			break
+		}
+	}
+
+	// The target tile is left to the cursor.
+	for i, tup := range line[1 : len(line)-1] {
+		c := ColorNeedsTarget
+		if blocked >= 0 && i >= blocked {
+			c = ColorRed
+		}
+		text.Draw(screen, "*", e.engine.Font, tup[0]*10, tup[1]*10, c)
+	}
+	if warning != "" {
+		text.Draw(screen, warning, e.engine.Font, x*10+10, y*10-10, ColorRed)
+	}
+}
+
+// Targets lists the visible hostiles sorted by their distance to the player.
+func (e *selectIndexHandler) Targets() []*actor {
+	targets := []*actor{}
+	for _, a := range e.engine.GameMap.Actors() {
+		if a != e.Player && e.Player.IsHostile(a) && e.engine.GameMap.Visible[a.X][a.Y] {
+			targets = append(targets, a)
+		}
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return e.Player.Distance(targets[i].X, targets[i].Y) < e.Player.Distance(targets[j].X, targets[j].Y)
+	})
+	return targets
+}
+
+// CycleTarget moves the cursor to the next visible hostile, or the previous
+// one when step is negative.
+func (e *selectIndexHandler) CycleTarget(step int) {
+	targets := e.Targets()
+	if len(targets) == 0 {
+		e.engine.MessageLog.AddMessage("No targets in sight.", ColorImpossible, true)
+		return
+	}
+	next := 0
+	if step < 0 {
+		next = len(targets) - 1
+	}
+	for i, t := range targets {
+		if t.X == e.engine.MouseLocation[0] && t.Y == e.engine.MouseLocation[1] {
+			next = (i + step + len(targets)) % len(targets)
+			break
+		}
+	}
+	t := targets[next]
+	e.engine.LastTarget = t
+	e.engine.MouseLocation = [2]int{t.X, t.Y}
+}
+
+// RememberTarget keeps whoever stands at (x, y) as the target to aim at
+// next time.
+func (e *selectIndexHandler) RememberTarget(x, y int) {
+	if a := e.engine.GameMap.GetAcotrAtLocation(x, y); a != nil && a != e.Player {
+		e.engine.LastTarget = a
+	}
+}
+
 func (e *selectIndexHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
 	state := e.EvKeyDown(keys)
 	if h, ok := state.(eventHandler); ok {
@@ -417,6 +1281,14 @@ func (e *selectIndexHandler) EvKeyDown(keys []ebiten.Key) interface{} {
 			e.engine.MouseLocation = [2]int{x, y}
 			return noneAction{}
 		}
+		if p == ebiten.KeyTab {
+			step := 1
+			if ebiten.IsKeyPressed(ebiten.KeyShiftLeft) || ebiten.IsKeyPressed(ebiten.KeyShiftRight) {
+				step = -1
+			}
+			e.CycleTarget(step)
+			return noneAction{}
+		}
 		e.askUserEventHandler.EvKeyDown(keys)
 	}
 	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
@@ -478,9 +1350,28 @@ func (e *lookHandler) OnIndexSelected(x, y int) eventHandler {
 	return &mainGameEventHandler{eventHandlerBase{engine: e.engine}}
 }
 
+// newTargetingHandler starts ranged targeting on the last target when it is
+// still in sight.
+func newTargetingHandler(e *engine) selectIndexHandler {
+	h := newSelectIndexHandler(e)
+	if t := e.LastTarget; t != nil && t.IsAlive() && t.Parent == e.GameMap && e.GameMap.Visible[t.X][t.Y] {
+		e.MouseLocation = [2]int{t.X, t.Y}
+	}
+	return h
+}
+
 type singleRangedAttackHandler struct {
 	selectIndexHandler
-	Callback func(x, y int) action
+	Projectile bool // the action flies along projectilePath, so preview it
+	Callback   func(x, y int) action
+}
+
+func newSingleRangedAttackHandler(e *engine, projectile bool, callback func(x, y int) action) *singleRangedAttackHandler {
+	return &singleRangedAttackHandler{
+		selectIndexHandler: newTargetingHandler(e),
+		Projectile:         projectile,
+		Callback:           callback,
+	}
 }
 
 func (e *singleRangedAttackHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
@@ -521,15 +1412,31 @@ func (e *singleRangedAttackHandler) OnMouseButtonDown() action {
 }
 
 func (e *singleRangedAttackHandler) OnIndexSelected(x, y int) action {
+	e.RememberTarget(x, y)
 	return e.Callback(x, y)
 }
 
+func (e *singleRangedAttackHandler) OnRender(screen *ebiten.Image) {
+	e.selectIndexHandler.OnRender(screen)
+	if e.Projectile {
+		e.RenderLineOfFire(screen)
+	}
+}
+
 type areaRangedAttackHandler struct {
 	selectIndexHandler
 	Radius   int
 	Callback func(x, y int) action
 }
 
+func newAreaRangedAttackHandler(e *engine, radius int, callback func(x, y int) action) *areaRangedAttackHandler {
+	return &areaRangedAttackHandler{
+		selectIndexHandler: newTargetingHandler(e),
+		Radius:             radius,
+		Callback:           callback,
+	}
+}
+
 func (e *areaRangedAttackHandler) HandleEvent(keys []ebiten.Key) (eventHandler, error) {
 	state := e.EvKeyDown(keys)
 	if h, ok := state.(eventHandler); ok {
@@ -548,8 +1455,14 @@ func (e *areaRangedAttackHandler) OnRender(screen *ebiten.Image) {
 	e.selectIndexHandler.OnRender(screen)
 
 	x, y := e.engine.MouseLocation[0], e.engine.MouseLocation[1]
-
-	drawRange(screen, x*10-e.Radius*10-10, y*10-e.Radius*10-20, int(math.Pow(float64(e.Radius), 2))*10, int(math.Pow(float64(e.Radius), 2))*10, e.engine.Font, ColorRed)
+	gm := e.engine.GameMap
+	for tile := range gm.BlastArea(x, y, e.Radius) {
+		// Unexplored tiles stay dark, or the preview would map them out.
+		if !gm.IsExplored(tile[0], tile[1]) {
+			continue
+		}
+		text.Draw(screen, string([]rune{0xdb}), e.engine.Font, tile[0]*10, tile[1]*10, ColorBlast)
+	}
 }
 
 func (e *areaRangedAttackHandler) EvKeyDown(keys []ebiten.Key) interface{} {
@@ -576,6 +1489,7 @@ func (e *areaRangedAttackHandler) OnMouseButtonDown() action {
 }
 
 func (e *areaRangedAttackHandler) OnIndexSelected(x, y int) action {
+	e.RememberTarget(x, y)
 	return e.Callback(x, y)
 }
 
@@ -784,23 +1698,3 @@ func fillWindow(window *ebiten.Image, width, height int, title string, f font.Fa
 		}
 	}
 }
-
-func drawRange(screen *ebiten.Image, x, y, width, height int, f font.Face, c color.RGBA) {
-	for w := x; w < x+width; w += 10 {
-		for h := y; h < y+height; h += 10 {
-			if w == x && h == y {
-				text.Draw(screen, string([]rune{0xda}), f, w, h+10, c) // left upper
-			} else if w == x+width-10 && h == y {
-				text.Draw(screen, string([]rune{0xbf}), f, w, h+10, c) // right upper
-			} else if w == x && h == y+height-10 {
-				text.Draw(screen, string([]rune{0xc0}), f, w, h+10, c) // left lower
-			} else if w == x+width-10 && h == y+height-10 {
-				text.Draw(screen, string([]rune{0xd9}), f, w, h+10, c) // right lower
-			} else if w == x || w == x+width-10 {
-				text.Draw(screen, string([]rune{0xb3}), f, w, h+10, c) // vertical line
-			} else if h == y || h == y+height-10 {
-				text.Draw(screen, string([]rune{0xc4}), f, w, h+10, c) // horizontal line
-			}
-		}
-	}
-}
@@ -2,10 +2,20 @@ package main
 
 import "fmt"
 
+// TotalWeight is the weight of the whole stack in tenths of a pound.
+func (e *item) TotalWeight() int {
+	return e.Weight * e.Quantity
+}
+
+func formatWeight(w int) string {
+	return fmt.Sprintf("%d.%d", w/10, w%10)
+}
+
 type inventory struct {
 	*baseComponent
 	Capacity int
 	Items    []*item
+	Burdened bool
 }
 
 func newInventory(capacity int) *inventory {
@@ -36,14 +46,94 @@ func (c *inventory) SetEntities(entities []entity) {
 	c.Items = items
 }
 
-func (c *inventory) Drop(drop *item) {
+func (c *inventory) Remove(remove *item) {
 	for i, it := range c.Items {
-		if it == drop {
+		if it == remove {
 			c.Items = append(c.Items[:i], c.Items[i+1:]...)
 			break
 		}
 	}
+}
+
+func (c *inventory) Weight() int {
+	total := 0
+	for _, it := range c.Items {
+		total += it.TotalWeight()
+	}
+	return total
+}
+
+// CarryLimit is how much the owner carries without being slowed down, which
+// grows with its strength. Nobody can carry more than half as much again.
+func (c *inventory) CarryLimit() int {
+	return 250 + c.Parent.(*actor).Fighter.BasePower*50
+}
+
+func (c *inventory) IsBurdened() bool {
+	return c.Weight() > c.CarryLimit()
+}
+
+func (c *inventory) CanCarry(it *item) bool {
+	return c.Weight()+it.TotalWeight() <= c.CarryLimit()*3/2
+}
+
+// UpdateBurden tells the player when their load starts or stops slowing
+// them down.
+func (c *inventory) UpdateBurden() {
+	burdened := c.IsBurdened()
+	if burdened == c.Burdened {
+		return
+	}
+	c.Burdened = burdened
+	if burdened {
+		c.Engine().MessageLog.AddMessage("You are burdened by your load.", ColorInvalid, true)
+	} else {
+		c.Engine().MessageLog.AddMessage("You are no longer burdened.", ColorWhite, true)
+	}
+}
+
+// Add puts it into the inventory, merging it into a matching stack if there
+// is one. It reports false when a new entry is needed but there's no room.
+func (c *inventory) Add(add *item) bool {
+	for _, it := range c.Items {
+		if it.CanStackWith(add) {
+			it.Quantity += add.Quantity
+			return true
+		}
+	}
+	if len(c.Items) >= c.Capacity {
+		return false
+	}
+	add.Parent = c
+	c.Items = append(c.Items, add)
+	return true
+}
+
+// Take removes a single item from the inventory, splitting it off its stack
+// when there is more than one.
+func (c *inventory) Take(it *item) *item {
+	if it.Quantity > 1 {
+		return it.Split(1)
+	}
+	c.Remove(it)
+	return it
+}
+
+func (c *inventory) Drop(it *item) {
+	drop := c.Take(it)
 	drop.Place(c.Parent.Entity().X, c.Parent.Entity().Y, c.GameMap())
 
-	c.Engine().MessageLog.AddMessage(fmt.Sprintf("You dropped the %s.", drop.Entity().Name), ColorWhite, true)
+	if c.Parent == c.Engine().Player {
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("You dropped the %s.", drop.DisplayName()), ColorWhite, true)
+	} else if c.GameMap().Visible[c.Parent.Entity().X][c.Parent.Entity().Y] {
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("The %s drops the %s.", c.Parent.Entity().Name, drop.DisplayName()), ColorWhite, true)
+	}
+}
+
+// DropAll scatters every carried item on the owner's tile.
+func (c *inventory) DropAll() {
+	for _, it := range c.Items {
+		it.Place(c.Parent.Entity().X, c.Parent.Entity().Y, c.GameMap())
+	}
+	c.Items = []*item{}
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+type level struct {
+	*baseComponent
+	CurrentLevel  int
+	CurrentXP     int
+	LevelUpBase   int
+	LevelUpFactor int
+	XPGiven       int
+}
+
+func newLevel(levelUpBase, levelUpFactor, xpGiven int) *level {
+	return &level{
+		baseComponent: &baseComponent{
+			Parent: nil,
+		},
+		CurrentLevel:  1,
+		CurrentXP:     0,
+		LevelUpBase:   levelUpBase,
+		LevelUpFactor: levelUpFactor,
+		XPGiven:       xpGiven,
+	}
+}
+
+// ExperienceToNextLevel grows with the level to the power of 1.5, so every
+// level takes a little longer than the previous one.
+func (c *level) ExperienceToNextLevel() int {
+	return c.LevelUpBase + int(math.Pow(float64(c.CurrentLevel), 1.5)*float64(c.LevelUpFactor))
+}
+
+func (c *level) RequiresLevelUp() bool {
+	return c.CurrentXP >= c.ExperienceToNextLevel()
+}
+
+func (c *level) AddXP(xp int) {
+	if xp == 0 || c.LevelUpBase == 0 {
+		return
+	}
+
+	c.CurrentXP += xp
+
+	c.Engine().MessageLog.AddMessage(fmt.Sprintf("You gain %d experience points.", xp), ColorWhite, true)
+
+	if c.RequiresLevelUp() {
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf("You advance to level %d!", c.CurrentLevel+1), ColorWhite, true)
+	}
+}
+
+func (c *level) IncreaseLevel() {
+	c.CurrentXP -= c.ExperienceToNextLevel()
+	c.CurrentLevel += 1
+}
+
+func (c *level) IncreaseMaxHP(amount int) {
+	fig := c.Parent.(*actor).Fighter
+	fig.MaxHP += amount
+	fig.HP += amount
+
+	c.Engine().MessageLog.AddMessage("Your health improves!", ColorWhite, true)
+
+	c.IncreaseLevel()
+}
+
+func (c *level) IncreasePower(amount int) {
+	c.Parent.(*actor).Fighter.BasePower += amount
+
+	c.Engine().MessageLog.AddMessage("You feel stronger!", ColorWhite, true)
+
+	c.IncreaseLevel()
+}
+
+func (c *level) IncreaseDefense(amount int) {
+	c.Parent.(*actor).Fighter.BaseDefense += amount
+
+	c.Engine().MessageLog.AddMessage("Your movements are getting swifter!", ColorWhite, true)
+
+	c.IncreaseLevel()
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"math/rand"
 	"os"
+	"time"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
@@ -27,14 +30,19 @@ const (
 )
 
 type Game struct {
-	keys []ebiten.Key
+	keys   []ebiten.Key
+	cursor [2]int
 }
 
 func (g *Game) Update() error {
 	g.keys = inpututil.AppendPressedKeys(g.keys[:0])
 
+	// The mouse only takes the cursor over when it moves, so that keyboard
+	// targeting isn't undone on the next frame.
 	mx, my := ebiten.CursorPosition()
-	if gameEngine.GameMap.InBounds(int(mx/10), int(my/10)) {
+	moved := g.cursor != [2]int{mx, my}
+	g.cursor = [2]int{mx, my}
+	if moved && gameEngine.GameMap.InBounds(int(mx/10), int(my/10)) {
 		gameEngine.MouseLocation = [2]int{int(mx / 10), int(my/10) + 1} // I don't know why +1 is needed, however this worked well
 	}
 	var err error
@@ -82,10 +90,31 @@ func init() {
 	if err != nil {
 		log.Fatal(err)
 	}
+}
+
+func main() {
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed for the dungeon, monsters and combat rolls")
+	autopickup := flag.String("autopickup", "gold", "comma separated kinds of items to pick up when walking over them (gold, potions, scrolls, wands, food, ammo)")
+	flag.Parse()
+
+	// Everything but combat rolls draws from the global source, so seed it
+	// too to make a run reproducible.
+	rand.Seed(*seed)
+
+	rules, err := parseAutopickup(*autopickup)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	player := newPlayer()
+	for _, it := range []*item{newDagger(), newLeatherArmor()} {
+		it.Parent = player.Inventory
+		player.Inventory.Items = append(player.Inventory.Items, it)
+		player.Equipment.Equip(it, false)
+	}
 
-	gameEngine = NewEngine(player, qbicfeetFont)
+	gameEngine = NewEngine(player, qbicfeetFont, *seed)
+	gameEngine.Autopickup = rules
 	gameEngine.GameMap = generateDungeon(
 		maxRooms,
 		roomMinSize,
@@ -108,9 +137,7 @@ func init() {
 			engine: gameEngine,
 		},
 	}
-}
 
-func main() {
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("Yet Another Roguelike Tutorial")
 
@@ -3,7 +3,6 @@ package main
 import (
 	"math"
 	"math/rand"
-	"time"
 )
 
 type rectangularRoom struct {
@@ -40,8 +39,6 @@ func (r rectangularRoom) Intersects(other rectangularRoom) bool {
 }
 
 func generateDungeon(maxRooms, roomMinSize, roomMaxSize, mapWidth, mapHeight, maxMonsterPerRoom, maxItemsPerRoom int, en *engine) *gameMap {
-	rand.Seed(time.Now().UnixNano())
-
 	player := en.Player
 	dungeon := newGameMap(en, mapWidth, mapHeight, []entity{})
 
@@ -75,6 +72,7 @@ func generateDungeon(maxRooms, roomMinSize, roomMaxSize, mapWidth, mapHeight, ma
 		if len(rooms) == 0 {
 			px, py := newRoom.Center()
 			player.Place(px, py, dungeon)
+			newDog().Spawn(dungeon, px+1, py)
 		} else {
 			x1, y1 := rooms[len(rooms)-1].Center()
 			x2, y2 := newRoom.Center()
@@ -85,7 +83,11 @@ func generateDungeon(maxRooms, roomMinSize, roomMaxSize, mapWidth, mapHeight, ma
 			}
 		}
 
-		placeEntities(newRoom, dungeon, maxMonsterPerRoom, maxItemsPerRoom)
+		if len(rooms) > 0 && len(dungeon.Shops) == 0 && rand.Intn(5) == 0 {
+			placeShop(newRoom, dungeon)
+		} else {
+			placeEntities(newRoom, dungeon, maxMonsterPerRoom, maxItemsPerRoom)
+		}
 
 		rooms = append(rooms, newRoom)
 	}
@@ -152,6 +154,88 @@ func bresenham(x1, y1, x2, y2 int) chan [2]int {
 	return ch
 }
 
+var monsterSpawnTable = []struct {
+	Weight int
+	New    func() *actor
+}{
+	{60, newOrc},
+	{10, newKoboldArcher},
+	{10, newGoblinShaman},
+	{15, newTroll},
+	{5, newFireElemental},
+}
+
+var itemSpawnTable = []struct {
+	Weight int
+	New    func() *item
+}{
+	{37, newHealthPortion},
+	{4, newRegenerationPotion},
+	{4, newHastePotion},
+	{3, newPoisonPotion},
+	{3, newBlindnessPotion},
+	{8, newRation},
+	{8, newApple},
+	{8, newFireballScroll},
+	{8, newConfusionScroll},
+	{4, newCharmScroll},
+	{8, newLightningScroll},
+	{6, newIdentifyScroll},
+	{4, newTeleportScroll},
+	{4, newMagicMappingScroll},
+	{4, newDetectMonstersScroll},
+	{3, newRechargeScroll},
+	{3, newWandOfLightning},
+	{3, newWandOfFire},
+	{3, newWandOfSlow},
+	{2, newWandOfParalysis},
+	{2, newWandOfDigging},
+	{5, newSword},
+	{3, newShortBow},
+	{3, newSling},
+	{6, newArrow},
+	{6, newSlingStone},
+	{6, newLeatherArmor},
+	{3, newChainMail},
+	{5, newShield},
+	{4, newRingOfStrength},
+	{4, newRingOfProtection},
+}
+
+func pickMonster() *actor {
+	total := 0
+	for _, s := range monsterSpawnTable {
+		total += s.Weight
+	}
+	r := rand.Intn(total)
+	for _, s := range monsterSpawnTable {
+		if r < s.Weight {
+			return s.New()
+		}
+		r -= s.Weight
+	}
+	return monsterSpawnTable[len(monsterSpawnTable)-1].New()
+}
+
+func pickItem() *item {
+	total := 0
+	for _, s := range itemSpawnTable {
+		total += s.Weight
+	}
+	r := rand.Intn(total)
+	for _, s := range itemSpawnTable {
+		if r < s.Weight {
+			it := s.New()
+			rollRarity(it)
+			return it
+		}
+		r -= s.Weight
+	}
+	it := itemSpawnTable[len(itemSpawnTable)-1].New()
+	rollRarity(it)
+	return it
+}
+
 func placeEntities(room rectangularRoom, dungeon *gameMap, maximumMonsters, maximumItems int) {
 	numberOfMonsters := rand.Intn(maximumMonsters + 1)
 	numberOfItems := rand.Intn(maximumItems)
@@ -163,11 +247,11 @@ func placeEntities(room rectangularRoom, dungeon *gameMap, maximumMonsters, maxi
 		for _, entity := range dungeon.Entities {
 			e := entity.Entity()
 			if !(e.X == x && e.Y == y) {
-				if rand.Float32() < 0.8 {
-					newOrc().Spawn(dungeon, x, y)
-				} else {
-					newTroll().Spawn(dungeon, x, y)
+				monster := pickMonster()
+				if rand.Intn(3) == 0 {
+					monster.Gold = rand.Intn(15) + 1
 				}
+				monster.Spawn(dungeon, x, y)
 				break
 			}
 		}
@@ -180,18 +264,43 @@ func placeEntities(room rectangularRoom, dungeon *gameMap, maximumMonsters, maxi
 		for _, entity := range dungeon.Entities {
 			e := entity.Entity()
 			if !(e.X == x && e.Y == y) {
-				itemChance := rand.Float32()
-				if itemChance < 0.7 {
-					newHealthPortion().Spawn(dungeon, x, y)
-				} else if itemChance < 0.8 {
-					newFireballScroll().Spawn(dungeon, x, y)
-				} else if itemChance < 0.9 {
-					newConfusionScroll().Spawn(dungeon, x, y)
-				} else {
-					newLightningScroll().Spawn(dungeon, x, y)
-				}
+				pickItem().Spawn(dungeon, x, y)
 				break
 			}
 		}
 	}
+
+	if rand.Intn(3) == 0 {
+		x := rand.Intn((room.X2-1)-(room.X1+1)) + room.X1 + 1
+		y := rand.Intn((room.Y2-1)-(room.Y1+1)) + room.Y1 + 1
+		newGoldPile(rand.Intn(20)+5).Spawn(dungeon, x, y)
+	}
+}
+
+// placeShop turns room into a shop with a shopkeeper standing in its middle
+// and a few items for sale around it.
+func placeShop(room rectangularRoom, dungeon *gameMap) {
+	keeper := newShopkeeperActor()
+	s := &shop{
+		Room:   room,
+		Keeper: keeper,
+	}
+	keeper.AI.(*shopkeeper).Shop = s
+	dungeon.Shops = append(dungeon.Shops, s)
+
+	cx, cy := room.Center()
+	keeper.Spawn(dungeon, cx, cy)
+
+	wtup, htup := room.Inner()
+	taken := map[[2]int]bool{{cx, cy}: true}
+	numberOfItems := rand.Intn(4) + 3
+	for i := 0; i < numberOfItems; i++ {
+		x := rand.Intn(wtup[1]-wtup[0]) + wtup[0]
+		y := rand.Intn(htup[1]-htup[0]) + htup[0]
+		if taken[[2]int{x, y}] {
+			continue
+		}
+		taken[[2]int{x, y}] = true
+		pickItem().Spawn(dungeon, x, y).(*item).Shop = s
+	}
 }
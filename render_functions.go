@@ -21,6 +21,56 @@ func RenderBar(screen *ebiten.Image, font font.Face, currentValue, maximumValue,
 	text.Draw(screen, fmt.Sprintf("HP: %d/%d", currentValue, maximumValue), font, 1, 450, ColorBarText)
 }
 
+func RenderLevel(screen *ebiten.Image, font font.Face, x, y int, a *actor) {
+	lvl := a.Level
+	text.Draw(screen, fmt.Sprintf("LV: %d XP: %d/%d", lvl.CurrentLevel, lvl.CurrentXP, lvl.ExperienceToNextLevel()), font, x*10+1, y*10, ColorWhite)
+}
+
+func RenderHunger(screen *ebiten.Image, font font.Face, x, y int, a *actor) {
+	state := a.Hunger.State()
+	c := ColorWhite
+	switch state {
+	case hungerHungry, hungerWeak:
+		c = ColorInvalid
+	case hungerStarving:
+		c = ColorPlayerDie
+	}
+	text.Draw(screen, state.String(), font, x*10+1, y*10, c)
+}
+
+func RenderGold(screen *ebiten.Image, font font.Face, x, y int, a *actor) {
+	text.Draw(screen, fmt.Sprintf("Gold: %d", a.Gold), font, x*10+1, y*10, ColorMenuTitle)
+}
+
+// RenderStatusEffects flows the effects left to right through a box of
+// width by height tiles, and notes how many didn't fit.
+func RenderStatusEffects(screen *ebiten.Image, font font.Face, x, y, width, height int, a *actor) {
+	effects := a.StatusEffects.Effects
+	col, row := 0, 0
+	for i, effect := range effects {
+		def := effectDefinitions[effect.Kind]
+		label := fmt.Sprintf("%s %d", def.Name, effect.Turns)
+		// The last row keeps room for the count of effects left out.
+		overflow := false
+		if col > 0 && col+len(label) > width {
+			if row == height-1 {
+				overflow = true
+			} else {
+				col, row = 0, row+1
+			}
+		}
+		if row == height-1 && i < len(effects)-1 && col+len(label)+4 > width {
+			overflow = true
+		}
+		if overflow {
+			text.Draw(screen, fmt.Sprintf("+%d", len(effects)-i), font, (x+col)*10+1, (y+row)*10, ColorWhite)
+			return
+		}
+		text.Draw(screen, label, font, (x+col)*10+1, (y+row)*10, def.Color)
+		col += len(label) + 1
+	}
+}
+
 func RenderNamesAtMouseLocation(screen *ebiten.Image, font font.Face, x, y int, e *engine) {
 	mx, my := e.MouseLocation[0], e.MouseLocation[1]
 
@@ -36,7 +86,19 @@ func getNamesAtLocation(x, y int, gm *gameMap) string {
 	for _, entity := range gm.Entities {
 		e := entity.Entity()
 		if e.X == x && e.Y == y {
-			names = append(names, e.Name)
+			name := e.Name
+			if it, ok := entity.(*item); ok {
+				name = it.DisplayName()
+				if it.Shop != nil && it.Shop.IsOpen() {
+					name = fmt.Sprintf("%s (%d gold)", name, it.Shop.BuyPrice(it))
+				}
+			}
+			if a, ok := entity.(*actor); ok {
+				if d, ok := a.AI.(*dormantEnemy); ok {
+					name = fmt.Sprintf("%s (%s)", name, d.State())
+				}
+			}
+			names = append(names, name)
 		}
 	}
 	return strings.Join(names, ",")
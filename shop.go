@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+type shop struct {
+	Room   rectangularRoom
+	Keeper *actor
+}
+
+func (s *shop) Contains(x, y int) bool {
+	wtup, htup := s.Room.Inner()
+	return wtup[0] <= x && x < wtup[1] && htup[0] <= y && y < htup[1]
+}
+
+// IsOpen reports whether the shopkeeper is still alive and willing to trade.
+func (s *shop) IsOpen() bool {
+	player := s.Keeper.GameMap().Engine.Player
+	return s.Keeper.IsAlive() && !s.Keeper.IsHostile(player)
+}
+
+// Stock lists the items lying in the shop that are for sale.
+func (s *shop) Stock() []*item {
+	stock := []*item{}
+	for _, it := range s.Keeper.GameMap().Items() {
+		if it.Shop == s {
+			stock = append(stock, it)
+		}
+	}
+	return stock
+}
+
+// BuyPrice is what the shop charges for the whole stack.
+func (s *shop) BuyPrice(it *item) int {
+	return it.Price() * it.Quantity
+}
+
+// SellPrice is what the shop pays for a single item of the stack.
+func (s *shop) SellPrice(it *item) int {
+	if price := it.Price() / 2; price > 0 {
+		return price
+	}
+	return 1
+}
+
+// Shelve puts an item the shop just bought on a random free tile of the shop.
+func (s *shop) Shelve(it *item) {
+	gm := s.Keeper.GameMap()
+	wtup, htup := s.Room.Inner()
+	x, y := s.Keeper.X, s.Keeper.Y
+	for i := 0; i < 20; i++ {
+		cx := rand.Intn(wtup[1]-wtup[0]) + wtup[0]
+		cy := rand.Intn(htup[1]-htup[0]) + htup[0]
+		if gm.GetBlockingEntityAtLocation(cx, cy) == nil {
+			x, y = cx, cy
+			break
+		}
+	}
+	it.Place(x, y, gm)
+	it.Shop = s
+}
+
+// Rob turns the shopkeeper against the thief.
+func (s *shop) Rob(thief *actor) {
+	if !s.IsOpen() {
+		return
+	}
+	s.Keeper.Faction = factionMonsters
+	s.Keeper.AI = NewHostileEnemy(s.Keeper)
+	s.Keeper.GameMap().Engine.MessageLog.AddMessage(fmt.Sprintf("The %s shouts: \"Thief!\"", s.Keeper.Name), ColorPlayerDie, true)
+}
+
+// Price is what a shop charges for one of the item, its Value raised by its
+// rarity. Shops buy everything back at half price.
+func (e *item) Price() int {
+	return e.Value * e.Rarity.valueMultiplier()
+}
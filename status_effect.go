@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+)
+
+type effectKind int
+
+const (
+	effectPoison effectKind = iota
+	effectRegeneration
+	effectHaste
+	effectSlow
+	effectBlindness
+	effectConfusion
+	effectParalysis
+	effectDetectMonsters
+)
+
+type stackRule int
+
+const (
+	stackRefresh   stackRule = iota // keep the longer duration
+	stackExtend                     // add the durations up
+	stackIntensify                  // add the magnitudes up and keep the longer duration
+)
+
+type statusEffect struct {
+	Kind      effectKind
+	Turns     int
+	Magnitude int
+}
+
+// effectDefinition describes how an effect kind behaves. Messages come in
+// pairs: the first one is shown for the player, the second one is formatted
+// with the name of any other actor.
+type effectDefinition struct {
+	Name          string
+	Color         color.RGBA
+	Stacking      stackRule
+	Cancels       []effectKind // effects removed when this one is applied
+	ApplyMessage  [2]string
+	ExpireMessage [2]string
+	OnApply       func(target *actor, effect *statusEffect)
+	OnTurn        func(target *actor, effect *statusEffect)
+	OnExpire      func(target *actor, effect *statusEffect)
+}
+
+var effectDefinitions = map[effectKind]effectDefinition{
+	effectPoison: {
+		Name:          "Poisoned",
+		Color:         color.RGBA{R: 0x7F, G: 0xFF, B: 0x00, A: 0xFF},
+		Stacking:      stackIntensify,
+		ApplyMessage:  [2]string{"You are poisoned!", "The %s is poisoned!"},
+		ExpireMessage: [2]string{"You are no longer poisoned.", "The %s is no longer poisoned."},
+		OnTurn: func(target *actor, effect *statusEffect) {
+			target.Fighter.TakeDamage(effect.Magnitude, damagePoison, nil)
+		},
+	},
+	effectRegeneration: {
+		Name:          "Regenerating",
+		Color:         ColorHealthRecovered,
+		Stacking:      stackRefresh,
+		ApplyMessage:  [2]string{"You feel your wounds closing.", "The %s's wounds start closing."},
+		ExpireMessage: [2]string{"You stop regenerating.", "The %s stops regenerating."},
+		OnTurn: func(target *actor, effect *statusEffect) {
+			target.Fighter.Heal(effect.Magnitude)
+		},
+	},
+	effectHaste: {
+		Name:          "Hasted",
+		Color:         color.RGBA{R: 0x3F, G: 0xBF, B: 0xFF, A: 0xFF},
+		Stacking:      stackExtend,
+		ApplyMessage:  [2]string{"You feel quick!", "The %s speeds up!"},
+		Cancels:       []effectKind{effectSlow},
+		ExpireMessage: [2]string{"You slow down to your normal speed.", "The %s slows down to its normal speed."},
+	},
+	effectSlow: {
+		Name:          "Slowed",
+		Color:         color.RGBA{R: 0xBF, G: 0x7F, B: 0x3F, A: 0xFF},
+		Stacking:      stackExtend,
+		ApplyMessage:  [2]string{"You feel sluggish.", "The %s slows down."},
+		Cancels:       []effectKind{effectHaste},
+		ExpireMessage: [2]string{"You are no longer slowed.", "The %s is no longer slowed."},
+	},
+	effectBlindness: {
+		Name:          "Blind",
+		Color:         color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF},
+		Stacking:      stackRefresh,
+		ApplyMessage:  [2]string{"You are blinded!", "The %s is blinded!"},
+		ExpireMessage: [2]string{"You can see again.", "The %s can see again."},
+		OnApply:       refreshPlayerFov,
+		OnExpire:      refreshPlayerFov,
+	},
+	effectConfusion: {
+		Name:          "Confused",
+		Color:         color.RGBA{R: 0xCF, G: 0x3F, B: 0xFF, A: 0xFF},
+		Stacking:      stackRefresh,
+		ApplyMessage:  [2]string{"You feel confused!", "The eyes of the %s look vacant, as it starts to stumble around!"},
+		ExpireMessage: [2]string{"You are no longer confused.", "The %s is no longer confused."},
+	},
+	effectParalysis: {
+		Name:          "Paralyzed",
+		Color:         color.RGBA{R: 0xFF, G: 0xFF, B: 0x7F, A: 0xFF},
+		Stacking:      stackRefresh,
+		ApplyMessage:  [2]string{"You are paralyzed!", "The %s is paralyzed!"},
+		ExpireMessage: [2]string{"You can move again.", "The %s can move again."},
+	},
+	effectDetectMonsters: {
+		Name:          "Detecting",
+		Color:         color.RGBA{R: 0xFF, G: 0x7F, B: 0x7F, A: 0xFF},
+		Stacking:      stackExtend,
+		ApplyMessage:  [2]string{"You sense the presence of monsters!", "The %s senses the presence of monsters!"},
+		ExpireMessage: [2]string{"You no longer sense monsters.", "The %s no longer senses monsters."},
+	},
+}
+
+// UsesMagnitude reports whether the strength of the effect depends on its
+// magnitude rather than only on how long it lasts.
+func (k effectKind) UsesMagnitude() bool {
+	return k == effectPoison || k == effectRegeneration
+}
+
+func refreshPlayerFov(target *actor, effect *statusEffect) {
+	if e := target.GameMap().Engine; target == e.Player {
+		e.UpdateFov()
+	}
+}
+
+type statusEffects struct {
+	*baseComponent
+	Effects []*statusEffect
+}
+
+func newStatusEffects() *statusEffects {
+	return &statusEffects{
+		baseComponent: &baseComponent{
+			Parent: nil,
+		},
+		Effects: []*statusEffect{},
+	}
+}
+
+func (c *statusEffects) Get(kind effectKind) *statusEffect {
+	for _, effect := range c.Effects {
+		if effect.Kind == kind {
+			return effect
+		}
+	}
+	return nil
+}
+
+func (c *statusEffects) Has(kind effectKind) bool {
+	return c.Get(kind) != nil
+}
+
+func (c *statusEffects) Apply(kind effectKind, turns, magnitude int) {
+	target := c.Parent.(*actor)
+	def := effectDefinitions[kind]
+
+	if effect := c.Get(kind); effect != nil {
+		switch def.Stacking {
+		case stackRefresh:
+			if turns > effect.Turns {
+				effect.Turns = turns
+			}
+			if magnitude > effect.Magnitude {
+				effect.Magnitude = magnitude
+			}
+		case stackExtend:
+			effect.Turns += turns
+		case stackIntensify:
+			effect.Magnitude += magnitude
+			if turns > effect.Turns {
+				effect.Turns = turns
+			}
+		}
+		return
+	}
+
+	for _, k := range def.Cancels {
+		c.Remove(k)
+	}
+	effect := &statusEffect{
+		Kind:      kind,
+		Turns:     turns,
+		Magnitude: magnitude,
+	}
+	c.Effects = append(c.Effects, effect)
+	c.report(def.ApplyMessage, ColorStatusEffectApplied)
+	if def.OnApply != nil {
+		def.OnApply(target, effect)
+	}
+}
+
+func (c *statusEffects) Remove(kind effectKind) {
+	effect := c.Get(kind)
+	if effect == nil {
+		return
+	}
+	for i, e := range c.Effects {
+		if e == effect {
+			c.Effects = append(c.Effects[:i], c.Effects[i+1:]...)
+			break
+		}
+	}
+
+	def := effectDefinitions[kind]
+	c.report(def.ExpireMessage, ColorWhite)
+	if def.OnExpire != nil {
+		def.OnExpire(c.Parent.(*actor), effect)
+	}
+}
+
+// Tick runs the per-turn hook of every effect and expires those that ran out.
+func (c *statusEffects) Tick() {
+	target := c.Parent.(*actor)
+	for _, effect := range append([]*statusEffect{}, c.Effects...) {
+		if !target.IsAlive() {
+			return
+		}
+		if def := effectDefinitions[effect.Kind]; def.OnTurn != nil {
+			def.OnTurn(target, effect)
+		}
+		effect.Turns -= 1
+		if effect.Turns <= 0 {
+			c.Remove(effect.Kind)
+		}
+	}
+}
+
+func (c *statusEffects) report(messages [2]string, fg color.RGBA) {
+	target := c.Parent.(*actor)
+	if target == c.Engine().Player {
+		c.Engine().MessageLog.AddMessage(messages[0], fg, true)
+	} else if c.GameMap().Visible[target.X][target.Y] {
+		c.Engine().MessageLog.AddMessage(fmt.Sprintf(messages[1], target.Name), fg, true)
+	}
+}